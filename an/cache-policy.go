@@ -0,0 +1,8 @@
+package an
+
+// CachePolicyType assigned numbers.
+const (
+	CachePolicyNoCache = 1
+
+	_ = "enumgen:CachePolicyType"
+)
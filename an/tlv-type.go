@@ -57,6 +57,8 @@ const (
 	TtLpGeoTagNDNSim       = 0x55
 	TtLpPitToken           = 0x62
 	TtLpPacket             = 0x64
+	TtLpNack               = 0x0320
+	TtLpNackReason         = 0x0321
 	TtLpNextHopFaceID      = 0x0330
 	TtLpIncomingFaceID     = 0x0331
 	TtLpCachePolicy        = 0x0334
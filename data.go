@@ -16,16 +16,22 @@ type Data struct {
 	Name             Name
 	ContentType      ContentType
 	Freshness        time.Duration
+	FinalBlock       NameComponent
 	Content          []byte
 	SigInfo          *SigInfo
 	SigValue         []byte
+	metaExtra        []tlv.Element // unrecognized MetaInfo elements
 }
 
+// FinalBlock is a MakeData argument that sets FinalBlockID.
+type FinalBlock NameComponent
+
 // MakeData creates a Data from flexible arguments.
 // Arguments can contain:
 //  - string or Name: set Name
 //  - ContentType
 //  - time.Duration: set Freshness
+//  - FinalBlock: set FinalBlockID
 //  - []byte: set Content
 //  - LpL3: copy PitToken and CongMark
 //  - Interest or *Interest: copy Name, set FreshnessPeriod if Interest has MustBeFresh, inherit LpL3
@@ -51,6 +57,8 @@ func MakeData(args ...interface{}) (data Data) {
 			data.ContentType = a
 		case time.Duration:
 			data.Freshness = a
+		case FinalBlock:
+			data.FinalBlock = NameComponent(a)
 		case []byte:
 			data.Content = a
 		case LpL3:
@@ -75,7 +83,10 @@ func (data Data) ToPacket() *Packet {
 }
 
 func (data Data) String() string {
-	return data.Name.String()
+	if !data.FinalBlock.Valid() {
+		return data.Name.String()
+	}
+	return data.Name.String() + "[FinalBlock=" + data.FinalBlock.String() + "]"
 }
 
 // ComputeDigest computes implicit digest of this Data.
@@ -211,6 +222,12 @@ func (data *Data) UnmarshalBinary(wire []byte) error {
 						return e
 					}
 					data.Freshness *= time.Millisecond
+				case an.TtFinalBlockID:
+					if e := tlv.Decode(field1.Value, &data.FinalBlock); e != nil {
+						return e
+					}
+				default:
+					data.metaExtra = append(data.metaExtra, field1.Element)
 				}
 			}
 			if e := d1.ErrUnlessEOF(); e != nil {
@@ -246,6 +263,16 @@ func (data Data) encodeSignedPortion() (wire []byte, e error) {
 	if data.Freshness > 0 {
 		metaFields = append(metaFields, tlv.MakeElementNNI(an.TtFreshnessPeriod, data.Freshness/time.Millisecond))
 	}
+	if data.FinalBlock.Valid() {
+		finalBlockV, e := tlv.Encode(data.FinalBlock)
+		if e != nil {
+			return nil, e
+		}
+		metaFields = append(metaFields, tlv.MakeElement(an.TtFinalBlockID, finalBlockV))
+	}
+	for _, extra := range data.metaExtra {
+		metaFields = append(metaFields, extra)
+	}
 	if len(metaFields) > 0 {
 		metaV, e := tlv.Encode(metaFields...)
 		if e != nil {
@@ -25,6 +25,13 @@ func TestDataEncode(t *testing.T) {
 	assert.NoError(e)
 	assert.Contains(string(wire),
 		string(bytesFromHex("name=0703080142 meta=1407 contenttype=180103 freshness=190209C4 content=1502C0C1")))
+
+	data = ndn.MakeData("/C", ndn.FinalBlock(ndn.ParseNameComponent("33=%05")))
+	wire, e = tlv.Encode(data)
+	assert.NoError(e)
+	assert.Contains(string(wire),
+		string(bytesFromHex("name=0703080143 meta=1405 finalblock=1A03210105")))
+	assert.Equal("/8=C[FinalBlock=seg=5]", data.String())
 }
 
 func TestDataDecode(t *testing.T) {
@@ -49,7 +56,22 @@ func TestDataDecode(t *testing.T) {
 	nameEqual(assert, "/B/0", data)
 	assert.EqualValues(3, data.ContentType)
 	assert.Equal(260*time.Millisecond, data.Freshness)
+	assert.Equal("8=1", data.FinalBlock.String())
 	assert.Equal([]byte{0xC0, 0xC1}, data.Content)
+
+	assert.NoError(tlv.Decode(bytesFromHex("0619 name=0703080143 "+
+		"meta=140B finalblock=1A03080132 unrecognized=F0020000 unrecognized=F100 "+
+		"siginfo=16031B0100 sigvalue=1700"), &pkt))
+	data = pkt.Data
+	assert.NotNil(data)
+	assert.Equal("8=2", data.FinalBlock.String())
+
+	// unrecognized MetaInfo elements are preserved
+	data.SigValue = nil
+	wire, e := tlv.Encode(*data)
+	assert.NoError(e)
+	assert.Contains(string(wire),
+		string(bytesFromHex("meta=140B finalblock=1A03080132 unrecognized=F0020000 unrecognized=F100")))
 }
 
 func TestDataSatisfy(t *testing.T) {
@@ -0,0 +1,283 @@
+package endpoint
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/eric135/go-ndn"
+	"github.com/eric135/go-ndn/an"
+	"github.com/eric135/go-ndn/l3"
+)
+
+// Error conditions.
+var (
+	ErrExpire     = errors.New("Interest expired")
+	ErrFaceClosed = errors.New("face closed")
+)
+
+// NackError indicates the Interest has been Nacked.
+type NackError struct {
+	Reason uint8
+}
+
+func (e NackError) Error() string {
+	return fmt.Sprintf("Interest Nacked~%s", an.NackReasonString(e.Reason))
+}
+
+// VerifyError indicates the Data has failed verification.
+type VerifyError struct {
+	Data *ndn.Data
+	Err  error
+}
+
+func (e VerifyError) Error() string {
+	return fmt.Sprintf("Data verification failed: %v", e.Err)
+}
+
+// Unwrap returns the underlying verification error.
+func (e VerifyError) Unwrap() error {
+	return e.Err
+}
+
+// ConsumerOptions contains arguments to Consume function.
+type ConsumerOptions struct {
+	// Fw specifies the L3 Forwarder.
+	// The default is the default Forwarder.
+	Fw l3.Forwarder
+
+	// Face specifies an L3 Face to send Interests on, bypassing the forwarder.
+	// If set, Fw is ignored, and the face's Rx() channel is taken over by the consumer;
+	// incoming packets that do not answer a pending Interest are dropped.
+	Face l3.Face
+
+	// Retx specifies retransmission policy.
+	// The default is disabling retransmission.
+	Retx RetxPolicy
+
+	// Verifier specifies a Data verifier.
+	// The default is no verification.
+	Verifier ndn.Verifier
+}
+
+func (opts *ConsumerOptions) applyDefaults() {
+	if opts.Fw == nil && opts.Face == nil {
+		opts.Fw = l3.GetDefaultForwarder()
+	}
+	if opts.Retx == nil {
+		opts.Retx = noRetx{}
+	}
+	if opts.Verifier == nil {
+		opts.Verifier = ndn.NopVerifier
+	}
+}
+
+// Consume retrieves a single piece of Data.
+//
+// The Interest is assigned a unique PIT token, and a fresh Nonce upon each retransmission.
+// It returns ErrExpire if the Interest times out, NackError if the Interest is Nacked,
+// or VerifyError if the Data fails verification.
+func Consume(ctx context.Context, interest ndn.Interest, opts ConsumerOptions) (data *ndn.Data, e error) {
+	opts.applyDefaults()
+	var d *consumerDemux
+	if opts.Face != nil {
+		d = getFaceDemux(opts.Face)
+	} else {
+		lface, e := newLFace(opts.Fw)
+		if e != nil {
+			return nil, e
+		}
+		defer lface.Close()
+		d = newLFaceDemux(lface)
+	}
+
+	token := allocPitToken()
+	reply := d.Register(token)
+	defer d.Unregister(token)
+
+	retxIntervals := opts.Retx.IntervalIterable(interest.ApplyDefaultLifetime())
+	var nackErr error
+L:
+	for {
+		var timer *time.Timer
+		rto := retxIntervals()
+		if rto > 0 {
+			timer = time.NewTimer(rto)
+		} else {
+			timer = time.NewTimer(interest.Lifetime)
+		}
+
+		interest.Nonce = ndn.NewNonce()
+		var lph ndn.LpL3
+		lph.PitToken = token
+		interestCopy := interest
+		if !d.Send(&ndn.Packet{Lp: lph, Interest: &interestCopy}) {
+			timer.Stop()
+			return nil, ErrFaceClosed
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, ctx.Err()
+			case <-timer.C:
+				if rto > 0 {
+					continue L
+				}
+				if nackErr != nil {
+					return nil, nackErr
+				}
+				return nil, ErrExpire
+			case pkt, ok := <-reply:
+				switch {
+				case !ok:
+					timer.Stop()
+					return nil, ErrFaceClosed
+				case pkt.Data != nil && pkt.Data.CanSatisfy(interest):
+					timer.Stop()
+					data = pkt.Data
+					break L
+				case pkt.Nack != nil && pkt.Nack.Interest.Nonce == interest.Nonce:
+					nackErr = NackError{Reason: pkt.Nack.Reason}
+					if rto == 0 { // no more retransmission
+						timer.Stop()
+						return nil, nackErr
+					}
+				}
+			}
+		}
+	}
+
+	if e := opts.Verifier.Verify(data); e != nil {
+		return nil, VerifyError{Data: data, Err: e}
+	}
+	return data, nil
+}
+
+var lastPitToken = rand.Uint32()
+
+// allocPitToken allocates a 4-octet PIT token that is unique within the process.
+// 4 octets is the longest PIT token permitted by the forwarder in non-PIT mode.
+func allocPitToken() []byte {
+	token := make([]byte, 4)
+	binary.BigEndian.PutUint32(token, atomic.AddUint32(&lastPitToken, 1))
+	return token
+}
+
+// consumerDemux dispatches incoming packets to pending Interests by PIT token.
+type consumerDemux struct {
+	send   func(pkt *ndn.Packet)
+	mutex  sync.Mutex
+	closed bool
+	reply  map[string]chan *ndn.Packet
+}
+
+func newConsumerDemux(send func(pkt *ndn.Packet)) *consumerDemux {
+	return &consumerDemux{
+		send:  send,
+		reply: make(map[string]chan *ndn.Packet),
+	}
+}
+
+// newLFaceDemux creates a consumerDemux on a logical face.
+func newLFaceDemux(lface *lFace) *consumerDemux {
+	d := newConsumerDemux(func(pkt *ndn.Packet) { lface.ep2fw <- pkt })
+	go func() {
+		for l3pkt := range lface.fw2ep {
+			d.Deliver(l3pkt.ToPacket())
+		}
+		d.Close()
+	}()
+	return d
+}
+
+var (
+	faceDemuxes     = make(map[l3.Face]*consumerDemux)
+	faceDemuxesLock sync.Mutex
+)
+
+// getFaceDemux returns the consumerDemux on an L3 Face, creating it if necessary.
+func getFaceDemux(face l3.Face) *consumerDemux {
+	faceDemuxesLock.Lock()
+	defer faceDemuxesLock.Unlock()
+	if d := faceDemuxes[face]; d != nil {
+		return d
+	}
+
+	d := newConsumerDemux(func(pkt *ndn.Packet) { face.Tx() <- pkt })
+	faceDemuxes[face] = d
+	go func() {
+		for pkt := range face.Rx() {
+			d.Deliver(pkt)
+		}
+		faceDemuxesLock.Lock()
+		delete(faceDemuxes, face)
+		faceDemuxesLock.Unlock()
+		d.Close()
+	}()
+	return d
+}
+
+// Register creates a channel to receive packets with a PIT token.
+// The channel is closed when the face is closed.
+func (d *consumerDemux) Register(token []byte) <-chan *ndn.Packet {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	ch := make(chan *ndn.Packet, 4)
+	if d.closed {
+		close(ch)
+	} else {
+		d.reply[string(token)] = ch
+	}
+	return ch
+}
+
+// Unregister removes a PIT token.
+func (d *consumerDemux) Unregister(token []byte) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	delete(d.reply, string(token))
+}
+
+// Send transmits a packet.
+// Returns false if the face is closed.
+func (d *consumerDemux) Send(pkt *ndn.Packet) bool {
+	d.mutex.Lock()
+	closed := d.closed
+	d.mutex.Unlock()
+	if closed {
+		return false
+	}
+	d.send(pkt)
+	return true
+}
+
+// Deliver dispatches an incoming packet.
+// If the receiver has not consumed a previous packet, the new packet is dropped.
+func (d *consumerDemux) Deliver(pkt *ndn.Packet) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if ch := d.reply[string(pkt.Lp.PitToken)]; ch != nil {
+		select {
+		case ch <- pkt:
+		default:
+		}
+	}
+}
+
+// Close indicates the face has been closed.
+func (d *consumerDemux) Close() {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.closed = true
+	for token, ch := range d.reply {
+		close(ch)
+		delete(d.reply, token)
+	}
+}
@@ -0,0 +1,160 @@
+package endpoint_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/eric135/go-ndn"
+	"github.com/eric135/go-ndn/an"
+	"github.com/eric135/go-ndn/endpoint"
+	"github.com/eric135/go-ndn/l3"
+)
+
+func TestConsume(t *testing.T) {
+	assert, require := makeAR(t)
+
+	fw := l3.NewForwarder()
+	var nonces []ndn.Nonce
+	defer startTestProducer(fw, func(pkt *ndn.Packet) ndn.L3Packet {
+		nonces = append(nonces, pkt.Interest.Nonce)
+		if len(nonces) <= 2 {
+			return nil
+		}
+		return makeReply(pkt, ndn.MakeData(pkt.Interest, []byte{0xC0}))
+	})()
+
+	data, e := endpoint.Consume(context.Background(), ndn.MakeInterest("/A", 200*time.Millisecond),
+		endpoint.ConsumerOptions{
+			Fw:   fw,
+			Retx: endpoint.RetxOptions{Limit: 2, Interval: 30 * time.Millisecond},
+		})
+	require.NoError(e)
+	nameEqual(assert, "/A", data)
+	assert.Equal([]byte{0xC0}, data.Content)
+
+	require.Len(nonces, 3)
+	assert.NotEqual(nonces[0], nonces[1])
+	assert.NotEqual(nonces[1], nonces[2])
+}
+
+func TestConsumeRetxLimit(t *testing.T) {
+	assert, _ := makeAR(t)
+
+	tests := []struct {
+		retx       endpoint.RetxPolicy
+		nInterests int
+	}{
+		{nil, 1},
+		{endpoint.RetxOptions{}, 1},
+		{endpoint.RetxOptions{Limit: 1, Interval: 50 * time.Millisecond}, 2},  // retx before timeout
+		{endpoint.RetxOptions{Limit: 1, Interval: 400 * time.Millisecond}, 2}, // retx after timeout
+	}
+	for i, tt := range tests {
+		fw := l3.NewForwarder()
+		var nInterests int32
+		closeProducer := startTestProducer(fw, func(pkt *ndn.Packet) ndn.L3Packet {
+			atomic.AddInt32(&nInterests, 1)
+			return nil
+		})
+
+		data, e := endpoint.Consume(context.Background(), ndn.MakeInterest("/A", 200*time.Millisecond),
+			endpoint.ConsumerOptions{Fw: fw, Retx: tt.retx})
+		assert.Nil(data, "%d", i)
+		assert.Equal(endpoint.ErrExpire, e, "%d", i)
+		assert.EqualValues(tt.nInterests, atomic.LoadInt32(&nInterests), "%d", i)
+		closeProducer()
+	}
+}
+
+func TestConsumeNack(t *testing.T) {
+	assert, _ := makeAR(t)
+
+	fw := l3.NewForwarder()
+	data, e := endpoint.Consume(context.Background(), ndn.MakeInterest("/A"), endpoint.ConsumerOptions{Fw: fw})
+	assert.Nil(data)
+	var nackErr endpoint.NackError
+	if assert.True(errors.As(e, &nackErr)) {
+		assert.EqualValues(an.NackNoRoute, nackErr.Reason)
+	}
+}
+
+func TestConsumeVerify(t *testing.T) {
+	assert, _ := makeAR(t)
+
+	fw := l3.NewForwarder()
+	defer startTestProducer(fw, func(pkt *ndn.Packet) ndn.L3Packet {
+		data := ndn.MakeData(pkt.Interest)
+		ndn.NullSigner.Sign(&data)
+		return makeReply(pkt, data)
+	})()
+
+	data, e := endpoint.Consume(context.Background(), ndn.MakeInterest("/A"), endpoint.ConsumerOptions{
+		Fw:       fw,
+		Verifier: ndn.DigestSigning,
+	})
+	assert.Nil(data)
+	var verifyErr endpoint.VerifyError
+	if assert.True(errors.As(e, &verifyErr)) {
+		nameEqual(assert, "/A", verifyErr.Data)
+	}
+	assert.True(errors.Is(e, ndn.ErrSigType))
+}
+
+func TestConsumeFace(t *testing.T) {
+	assert, require := makeAR(t)
+
+	trA, trB := l3.NewMemoryTransportPair(l3.MemoryTransportConfig{})
+	faceA, e := l3.NewFace(trA)
+	require.NoError(e)
+	faceB, e := l3.NewFace(trB)
+	require.NoError(e)
+	receivedZ := make(chan bool)
+	go servePackets(faceB, func(pkt *ndn.Packet) ndn.L3Packet {
+		if pkt.Interest.Name.Compare(ndn.ParseName("/Z")) == 0 {
+			close(receivedZ)
+			return nil
+		}
+		time.Sleep(10 * time.Millisecond)
+		return makeReply(pkt, ndn.MakeData(pkt.Interest))
+	})
+	defer close(faceB.Tx())
+
+	results := make(chan error)
+	for _, name := range []string{"/A", "/B", "/C"} {
+		go func(name string) {
+			data, e := endpoint.Consume(context.Background(), ndn.MakeInterest(name), endpoint.ConsumerOptions{Face: faceA})
+			if e == nil && data.Name.Compare(ndn.ParseName(name)) != 0 {
+				e = errors.New("unexpected Data name")
+			}
+			results <- e
+		}(name)
+	}
+	for i := 0; i < 3; i++ {
+		assert.NoError(<-results)
+	}
+
+	go func() {
+		_, e := endpoint.Consume(context.Background(), ndn.MakeInterest("/Z"), endpoint.ConsumerOptions{Face: faceA})
+		results <- e
+	}()
+	<-receivedZ
+	close(faceA.Tx())
+	assert.Equal(endpoint.ErrFaceClosed, <-results)
+}
+
+func TestConsumeCancel(t *testing.T) {
+	assert, _ := makeAR(t)
+
+	fw := l3.NewForwarder()
+	defer startTestProducer(fw, func(pkt *ndn.Packet) ndn.L3Packet { return nil })()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	data, e := endpoint.Consume(ctx, ndn.MakeInterest("/A", 200*time.Millisecond),
+		endpoint.ConsumerOptions{Fw: fw, Retx: endpoint.RetxOptions{Limit: 2}})
+	assert.Nil(data)
+	assert.Equal(context.DeadlineExceeded, e)
+}
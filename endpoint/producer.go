@@ -0,0 +1,204 @@
+package endpoint
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/eric135/go-ndn"
+	"github.com/eric135/go-ndn/an"
+	"github.com/eric135/go-ndn/l3"
+)
+
+// Error conditions.
+var (
+	ErrNoHandler = errors.New("Handler is missing")
+)
+
+type producerNackError uint8
+
+func (producerNackError) Error() string {
+	return "Nack"
+}
+
+// ReplyNack causes the producer to return a Nack packet.
+func ReplyNack(reason uint8) error {
+	return producerNackError(reason)
+}
+
+// ProducerHandler is a producer handler function.
+//  - If it returns an error created with ReplyNack(), a Nack is sent in reply to the Interest.
+//  - If it returns a Data that satisfies the Interest, the Data is sent in reply to the Interest.
+//  - Otherwise, nothing is sent.
+type ProducerHandler func(ctx context.Context, interest ndn.Interest) (ndn.Data, error)
+
+// ProducerOptions contains arguments to Produce function.
+type ProducerOptions struct {
+	// Prefix is the name prefix of the producer.
+	Prefix ndn.Name
+
+	// NoAdvertise disables prefix announcement.
+	// The default is announcing the prefix.
+	NoAdvertise bool
+
+	// Handler is a function to handle Interests under the prefix.
+	// This may be invoked concurrently.
+	Handler ProducerHandler
+
+	// Concurrency is the maximum number of concurrent Handler invocations.
+	// When this limit is reached, incoming Interests are queued until a Handler invocation completes.
+	// The default is DefaultProducerConcurrency.
+	Concurrency int
+
+	// QueueCapacity is the maximum number of Interests waiting for a Handler invocation.
+	// When the queue is full, incoming Interests are dropped.
+	// The default is DefaultProducerQueueCapacity.
+	QueueCapacity int
+
+	// Fw specifies the L3 Forwarder.
+	// The default is the default Forwarder.
+	Fw l3.Forwarder
+
+	// DataSigner automatically signs Data packets unless already signed.
+	// The default is keeping the Null signature.
+	DataSigner ndn.Signer
+}
+
+func (opts *ProducerOptions) applyDefaults() {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = DefaultProducerConcurrency
+	}
+	if opts.QueueCapacity <= 0 {
+		opts.QueueCapacity = DefaultProducerQueueCapacity
+	}
+	if opts.Fw == nil {
+		opts.Fw = l3.GetDefaultForwarder()
+	}
+}
+
+// ProducerOptions defaults.
+const (
+	DefaultProducerConcurrency   = 64
+	DefaultProducerQueueCapacity = 256
+)
+
+// Produce starts a producer.
+// The producer stops when ctx is canceled or Close is called, at which time its route and announcement are removed.
+func Produce(ctx context.Context, opts ProducerOptions) (Producer, error) {
+	opts.applyDefaults()
+	if opts.Handler == nil {
+		return nil, ErrNoHandler
+	}
+
+	face, e := newLFace(opts.Fw)
+	if e != nil {
+		return nil, e
+	}
+	face.fwFace.AddRoute(opts.Prefix, 0)
+	if !opts.NoAdvertise {
+		face.fwFace.AddAnnouncement(opts.Prefix)
+	}
+
+	ctx1, cancel := context.WithCancel(ctx)
+	p := &producer{
+		ProducerOptions: opts,
+		face:            face,
+		close:           cancel,
+		workers:         make(chan struct{}, opts.Concurrency),
+	}
+	go p.loop(ctx1)
+	return p, nil
+}
+
+// Producer represents a running producer.
+type Producer interface {
+	io.Closer
+}
+
+type producer struct {
+	ProducerOptions
+	face    *lFace
+	close   context.CancelFunc
+	workers chan struct{} // semaphore of running handlers
+}
+
+func (p *producer) Close() error {
+	p.close()
+	return nil
+}
+
+func (p *producer) loop(ctx context.Context) {
+	var wg sync.WaitGroup
+	defer func() {
+		wg.Wait()
+		p.face.Close()
+		p.close()
+	}()
+
+	// Incoming Interests must be received even if all workers are busy, because the forwarder would otherwise block.
+	var queue []*ndn.Packet
+	for {
+		var workersC chan<- struct{}
+		if len(queue) > 0 {
+			workersC = p.workers
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case l3pkt := <-p.face.fw2ep:
+			pkt := l3pkt.ToPacket()
+			if pkt.Interest == nil || len(queue) >= p.QueueCapacity {
+				continue
+			}
+			queue = append(queue, pkt)
+		case workersC <- struct{}{}:
+			pkt := queue[0]
+			queue[0] = nil
+			queue = queue[1:]
+			wg.Add(1)
+			go p.handleInterest(ctx, &wg, pkt)
+		}
+	}
+}
+
+func (p *producer) handleInterest(ctx context.Context, wg *sync.WaitGroup, pkt *ndn.Packet) {
+	defer func() {
+		<-p.workers
+		wg.Done()
+	}()
+
+	interest := pkt.Interest
+	if !p.Prefix.IsPrefixOf(interest.Name) {
+		return
+	}
+
+	ctx1, cancel := context.WithTimeout(ctx, interest.ApplyDefaultLifetime())
+	defer cancel()
+	data, e := p.Handler(ctx1, *interest)
+
+	var reply *ndn.Packet
+	if e != nil {
+		if nackError, ok := e.(producerNackError); ok {
+			nack := ndn.MakeNack(*interest, uint8(nackError))
+			reply = nack.ToPacket()
+		}
+	} else if data.CanSatisfy(*interest) {
+		if (data.SigInfo == nil || data.SigInfo.Type == an.SignatureNull) && p.DataSigner != nil {
+			if e := p.DataSigner.Sign(&data); e != nil {
+				return
+			}
+		}
+		reply = &ndn.Packet{Data: &data}
+	}
+
+	if reply == nil {
+		return
+	}
+	reply.Lp.PitToken = pkt.Lp.PitToken
+	select {
+	case <-ctx.Done():
+	case p.face.ep2fw <- reply:
+	}
+}
@@ -0,0 +1,366 @@
+package endpoint_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/eric135/go-ndn"
+	"github.com/eric135/go-ndn/an"
+	"github.com/eric135/go-ndn/endpoint"
+	"github.com/eric135/go-ndn/keychain"
+	"github.com/eric135/go-ndn/keychain/eckey"
+	"github.com/eric135/go-ndn/l3"
+)
+
+func TestSignVerify(t *testing.T) {
+	fw := l3.NewForwarder()
+	assert, require := makeAR(t)
+
+	makeSignerVerifier := func(name string) (ndn.Signer, ndn.Verifier) {
+		keyName := keychain.ToKeyName(ndn.ParseName(name))
+		pvt, e := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(e)
+		signer, e := eckey.NewPrivateKey(keyName, pvt)
+		require.NoError(e)
+		verifier, e := eckey.NewPublicKey(keyName, &pvt.PublicKey)
+		require.NoError(e)
+		return signer, verifier
+	}
+	signer1, verifier1 := makeSignerVerifier("/K1")
+	signer2, verifier2 := makeSignerVerifier("/K2")
+
+	p, e := endpoint.Produce(context.Background(), endpoint.ProducerOptions{
+		Prefix: ndn.ParseName("/A"),
+		Handler: func(ctx context.Context, interest ndn.Interest) (ndn.Data, error) {
+			data := ndn.MakeData(interest.Name)
+			if interest.Name.Get(-1).Value[0] == '2' {
+				if e := signer2.Sign(&data); e != nil {
+					return ndn.Data{}, e
+				}
+			}
+			return data, nil
+		},
+		Fw:         fw,
+		DataSigner: signer1,
+	})
+	require.NoError(e)
+	defer p.Close()
+
+	data1, e := endpoint.Consume(context.Background(), ndn.MakeInterest("/A/1"),
+		endpoint.ConsumerOptions{Fw: fw, Verifier: verifier1})
+	if assert.NoError(e) {
+		nameEqual(assert, "/A/1", data1)
+	}
+
+	_, e = endpoint.Consume(context.Background(), ndn.MakeInterest("/A/1"),
+		endpoint.ConsumerOptions{Fw: fw, Verifier: verifier2})
+	var verifyErr endpoint.VerifyError
+	assert.True(errors.As(e, &verifyErr))
+
+	data2, e := endpoint.Consume(context.Background(), ndn.MakeInterest("/A/2"),
+		endpoint.ConsumerOptions{Fw: fw, Verifier: verifier2})
+	if assert.NoError(e) {
+		nameEqual(assert, "/A/2", data2)
+	}
+}
+
+func TestProducerNonMatch(t *testing.T) {
+	defer l3.DeleteDefaultForwarder()
+	assert, require := makeAR(t)
+
+	p, e := endpoint.Produce(context.Background(), endpoint.ProducerOptions{
+		Prefix: ndn.ParseName("/A"),
+		Handler: func(ctx context.Context, interest ndn.Interest) (ndn.Data, error) {
+			return ndn.MakeData("/A/0"), nil
+		},
+	})
+	require.NoError(e)
+	defer p.Close()
+
+	data, e := endpoint.Consume(context.Background(), ndn.MakeInterest("/A/9", 100*time.Millisecond),
+		endpoint.ConsumerOptions{})
+	assert.Nil(data)
+	assert.Equal(endpoint.ErrExpire, e)
+}
+
+func TestProducerNack(t *testing.T) {
+	assert, require := makeAR(t)
+
+	fw := l3.NewForwarder()
+	p, e := endpoint.Produce(context.Background(), endpoint.ProducerOptions{
+		Prefix: ndn.ParseName("/A"),
+		Handler: func(ctx context.Context, interest ndn.Interest) (ndn.Data, error) {
+			return ndn.Data{}, endpoint.ReplyNack(an.NackCongestion)
+		},
+		Fw: fw,
+	})
+	require.NoError(e)
+	defer p.Close()
+
+	_, e = endpoint.Consume(context.Background(), ndn.MakeInterest("/A/1"), endpoint.ConsumerOptions{Fw: fw})
+	var nackErr endpoint.NackError
+	if assert.True(errors.As(e, &nackErr)) {
+		assert.EqualValues(an.NackCongestion, nackErr.Reason)
+	}
+}
+
+func TestProducerFace(t *testing.T) {
+	assert, require := makeAR(t)
+
+	fw := l3.NewForwarder()
+	p, e := endpoint.Produce(context.Background(), endpoint.ProducerOptions{
+		Prefix:  ndn.ParseName("/A"),
+		Handler: producerHandlerNever,
+		Fw:      fw,
+	})
+	require.NoError(e)
+	defer p.Close()
+
+	entries := fw.Fib().Entries()
+	require.Len(entries, 1)
+	require.Len(entries[0].Nexthops, 1)
+	face := entries[0].Nexthops[0].Face
+	assert.Equal(l3.TransportUp, face.State())
+	closer := face.OnStateChange(func(st l3.TransportState) {
+		assert.Fail("state should not change")
+	})
+	require.NotNil(closer)
+	assert.NoError(closer.Close())
+}
+
+func TestProducerConcurrent(t *testing.T) {
+	defer l3.DeleteDefaultForwarder()
+	assert, require := makeAR(t)
+
+	var pCompleted, pCanceled int32
+	pCtx, pCancel := context.WithTimeout(context.Background(), 150*time.Millisecond)
+	defer pCancel()
+	p, e := endpoint.Produce(pCtx, endpoint.ProducerOptions{
+		Prefix: ndn.ParseName("/A"),
+		Handler: func(ctx context.Context, interest ndn.Interest) (ndn.Data, error) {
+			delay, _ := strconv.Atoi(string(interest.Name.Get(-1).Value))
+			select {
+			case <-time.After(time.Duration(delay) * time.Millisecond):
+				atomic.AddInt32(&pCompleted, 1)
+			case <-ctx.Done():
+				atomic.AddInt32(&pCanceled, 1)
+			}
+			return ndn.MakeData(interest), nil
+		},
+		Concurrency: 1000,
+	})
+	require.NoError(e)
+	defer p.Close()
+
+	var cWait sync.WaitGroup
+	var cData, cExpire int32
+	for i := 0; i < 250; i++ {
+		cWait.Add(1)
+		go func(i int) {
+			defer cWait.Done()
+			interest := ndn.MakeInterest(fmt.Sprintf("/A/%d", i), 300*time.Millisecond)
+			data, e := endpoint.Consume(context.Background(), interest, endpoint.ConsumerOptions{})
+			if data != nil {
+				atomic.AddInt32(&cData, 1)
+			} else if assert.Equal(endpoint.ErrExpire, e) {
+				atomic.AddInt32(&cExpire, 1)
+			}
+		}(i)
+	}
+
+	cWait.Wait()
+	assert.EqualValues(250, cData+cExpire)
+	assert.InDelta(250, pCompleted+pCanceled, 70)
+	assert.InDelta(150, pCompleted, 70)
+	assert.InDelta(pCompleted, cData, 70)
+	assert.InDelta(pCanceled, cExpire, 70)
+}
+
+func TestProducerConcurrencyLimit(t *testing.T) {
+	assert, require := makeAR(t)
+
+	fw := l3.NewForwarder()
+	var nRunning, maxRunning int32
+	p, e := endpoint.Produce(context.Background(), endpoint.ProducerOptions{
+		Prefix: ndn.ParseName("/A"),
+		Handler: func(ctx context.Context, interest ndn.Interest) (ndn.Data, error) {
+			n := atomic.AddInt32(&nRunning, 1)
+			for {
+				max := atomic.LoadInt32(&maxRunning)
+				if n <= max || atomic.CompareAndSwapInt32(&maxRunning, max, n) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+			atomic.AddInt32(&nRunning, -1)
+			return ndn.MakeData(interest), nil
+		},
+		Concurrency: 4,
+		Fw:          fw,
+	})
+	require.NoError(e)
+	defer p.Close()
+
+	var cWait sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		cWait.Add(1)
+		go func(i int) {
+			defer cWait.Done()
+			_, e := endpoint.Consume(context.Background(), ndn.MakeInterest(fmt.Sprintf("/A/%d", i)),
+				endpoint.ConsumerOptions{Fw: fw})
+			assert.NoError(e)
+		}(i)
+	}
+	cWait.Wait()
+	assert.EqualValues(4, atomic.LoadInt32(&maxRunning))
+}
+
+func TestProducerFlood(t *testing.T) {
+	assert, require := makeAR(t)
+
+	fw := l3.NewForwarder()
+	p, e := endpoint.Produce(context.Background(), endpoint.ProducerOptions{
+		Prefix: ndn.ParseName("/A"),
+		Handler: func(ctx context.Context, interest ndn.Interest) (ndn.Data, error) {
+			time.Sleep(200 * time.Microsecond)
+			return ndn.MakeData(interest), nil
+		},
+		Concurrency:   1,
+		QueueCapacity: 16,
+		Fw:            fw,
+	})
+	require.NoError(e)
+	defer p.Close()
+
+	trA, trB := l3.NewMemoryTransportPair(l3.MemoryTransportConfig{})
+	_, e = fw.AddTransport(trA)
+	require.NoError(e)
+	face, e := l3.NewFace(trB)
+	require.NoError(e)
+	defer close(face.Tx())
+
+	var nData int32
+	go func() {
+		for pkt := range face.Rx() {
+			if pkt.Data != nil {
+				atomic.AddInt32(&nData, 1)
+			}
+		}
+	}()
+	for i := 0; i < 3000; i++ {
+		var lph ndn.LpL3
+		lph.PitToken = ndn.PitTokenFromUint(uint64(i))
+		face.Tx() <- ndn.MakeInterest(fmt.Sprintf("/A/%d", i), lph)
+	}
+
+	// forwarder and producer remain responsive after the flood
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, e = endpoint.Consume(ctx, ndn.MakeInterest("/A/last"), endpoint.ConsumerOptions{
+		Fw:   fw,
+		Retx: endpoint.RetxOptions{Limit: 10, Interval: 100 * time.Millisecond},
+	})
+	assert.NoError(e)
+	assert.Greater(atomic.LoadInt32(&nData), int32(16))
+}
+
+var producerHandlerNever endpoint.ProducerHandler = func(ctx context.Context, interest ndn.Interest) (ndn.Data, error) {
+	panic("this ProducerHandler should not be invoked")
+}
+
+type readvertiseDestinationMock struct {
+	mutex      sync.Mutex
+	advertised []ndn.Name
+	withdrawn  []ndn.Name
+}
+
+func (dest *readvertiseDestinationMock) Advertise(prefix ndn.Name) error {
+	dest.mutex.Lock()
+	defer dest.mutex.Unlock()
+	dest.advertised = append(dest.advertised, prefix)
+	return nil
+}
+
+func (dest *readvertiseDestinationMock) Withdraw(prefix ndn.Name) error {
+	dest.mutex.Lock()
+	defer dest.mutex.Unlock()
+	dest.withdrawn = append(dest.withdrawn, prefix)
+	return nil
+}
+
+func (dest *readvertiseDestinationMock) Lists() (advertised, withdrawn []ndn.Name) {
+	dest.mutex.Lock()
+	defer dest.mutex.Unlock()
+	return dest.advertised, dest.withdrawn
+}
+
+func TestProducerAdvertise(t *testing.T) {
+	defer l3.DeleteDefaultForwarder()
+	assert, require := makeAR(t)
+
+	var dest readvertiseDestinationMock
+	l3.GetDefaultForwarder().AddReadvertiseDestination(&dest)
+
+	p1, e := endpoint.Produce(context.Background(), endpoint.ProducerOptions{
+		Prefix:  ndn.ParseName("/A"),
+		Handler: producerHandlerNever,
+	})
+	require.NoError(e)
+	advertised, _ := dest.Lists()
+	if assert.Len(advertised, 1) {
+		nameEqual(assert, advertised[0], "/A")
+	}
+
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	_, e = endpoint.Produce(ctx2, endpoint.ProducerOptions{
+		Prefix:  ndn.ParseName("/A"),
+		Handler: producerHandlerNever,
+	})
+	require.NoError(e)
+	advertised, _ = dest.Lists()
+	assert.Len(advertised, 1)
+
+	p1.Close()
+	time.Sleep(50 * time.Millisecond)
+	_, withdrawn := dest.Lists()
+	assert.Len(withdrawn, 0)
+
+	cancel2()
+	time.Sleep(50 * time.Millisecond) // producer stops asynchronously
+	_, withdrawn = dest.Lists()
+	if assert.Len(withdrawn, 1) {
+		nameEqual(assert, withdrawn[0], "/A")
+	}
+	assert.Len(l3.GetDefaultForwarder().Fib().Entries(), 0)
+}
+
+func TestProducerNoAdvertise(t *testing.T) {
+	defer l3.DeleteDefaultForwarder()
+	assert, require := makeAR(t)
+
+	var dest readvertiseDestinationMock
+	l3.GetDefaultForwarder().AddReadvertiseDestination(&dest)
+
+	p, e := endpoint.Produce(context.Background(), endpoint.ProducerOptions{
+		Prefix:      ndn.ParseName("/A"),
+		NoAdvertise: true,
+		Handler:     producerHandlerNever,
+	})
+	require.NoError(e)
+	advertised, _ := dest.Lists()
+	assert.Len(advertised, 0)
+
+	p.Close()
+	time.Sleep(50 * time.Millisecond) // producer.Close is asynchronous
+	_, withdrawn := dest.Lists()
+	assert.Len(withdrawn, 0)
+}
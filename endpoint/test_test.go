@@ -0,0 +1,46 @@
+package endpoint_test
+
+import (
+	"github.com/eric135/go-ndn"
+	"github.com/eric135/go-ndn/l3"
+	"github.com/eric135/go-ndn/ndntestenv"
+	"github.com/usnistgov/ndn-dpdk/core/testenv"
+)
+
+var (
+	makeAR    = testenv.MakeAR
+	nameEqual = ndntestenv.NameEqual
+)
+
+// startTestProducer adds a face to the forwarder with "/" route.
+// handler is invoked for each incoming Interest; if it returns a packet, the packet is sent on the face.
+func startTestProducer(fw l3.Forwarder, handler func(pkt *ndn.Packet) ndn.L3Packet) (close func()) {
+	trA, trB := l3.NewMemoryTransportPair(l3.MemoryTransportConfig{})
+	ff, e := fw.AddTransport(trA)
+	if e != nil {
+		panic(e)
+	}
+	ff.AddRoute(ndn.ParseName("/"), 0)
+	face, _ := l3.NewFace(trB)
+	go servePackets(face, handler)
+	return func() { ff.Close() }
+}
+
+// servePackets invokes handler for each Interest received on face.
+func servePackets(face l3.Face, handler func(pkt *ndn.Packet) ndn.L3Packet) {
+	for pkt := range face.Rx() {
+		if pkt.Interest == nil {
+			continue
+		}
+		if reply := handler(pkt); reply != nil {
+			face.Tx() <- reply
+		}
+	}
+}
+
+// makeReply creates a reply packet with the PIT token of the request.
+func makeReply(request *ndn.Packet, l3pkt ndn.L3Packet) ndn.L3Packet {
+	reply := l3pkt.ToPacket()
+	reply.Lp.PitToken = request.Lp.PitToken
+	return reply
+}
@@ -21,4 +21,6 @@ var (
 	ErrKeyLocator     = errors.New("bad KeyLocator")
 	ErrSigNonce       = errors.New("bad SigNonce")
 	ErrSigValue       = errors.New("bad SigValue")
+	ErrValidityPeriod = errors.New("bad ValidityPeriod")
+	ErrPrefixAnn      = errors.New("bad PrefixAnnouncement")
 )
@@ -3,6 +3,7 @@ module github.com/eric135/go-ndn
 go 1.15
 
 require (
+	github.com/gorilla/websocket v1.4.2
 	github.com/jwangsadinata/go-multimap v0.0.0-20190620162914-c29f3d7f33b6
 	github.com/stretchr/testify v1.6.1
 	github.com/usnistgov/ndn-dpdk v0.0.0-20201112222634-d97aede17eb2
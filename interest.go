@@ -23,7 +23,7 @@ type Interest struct {
 	ForwardingHint ForwardingHint
 	Nonce          Nonce
 	Lifetime       time.Duration
-	HopLimit       HopLimit
+	HopLimit       *HopLimit // nil means omitted
 	AppParameters  []byte
 	SigInfo        *SigInfo
 	SigValue       []byte
@@ -60,7 +60,7 @@ func MakeInterest(args ...interface{}) (interest Interest) {
 		case time.Duration:
 			interest.Lifetime = a
 		case HopLimit:
-			interest.HopLimit = a
+			interest.HopLimit = &a
 		case []byte:
 			interest.AppParameters = a
 		case LpL3:
@@ -191,8 +191,8 @@ func (interest Interest) MarshalTlv() (typ uint32, value []byte, e error) {
 		}
 		fields = append(fields, tlv.MakeElementNNI(an.TtInterestLifetime, lifetime/time.Millisecond))
 	}
-	if interest.HopLimit != 0 {
-		fields = append(fields, interest.HopLimit)
+	if interest.HopLimit != nil {
+		fields = append(fields, *interest.HopLimit)
 	}
 	fields = append(fields, interest.encodeParamsPortion())
 	return tlv.EncodeTlv(an.TtInterest, fields)
@@ -227,9 +227,11 @@ func (interest *Interest) UnmarshalBinary(wire []byte) error {
 			}
 			interest.Lifetime *= time.Millisecond
 		case an.TtHopLimit:
-			if e := field.UnmarshalValue(&interest.HopLimit); e != nil {
+			var hopLimit HopLimit
+			if e := field.UnmarshalValue(&hopLimit); e != nil {
 				return e
 			}
+			interest.HopLimit = &hopLimit
 		case an.TtApplicationParameters:
 			interest.AppParameters = field.Value
 			paramsPortion = field.WireAfter()
@@ -53,5 +53,14 @@ func TestInterestDecode(t *testing.T) {
 	assert.Len(interest.ForwardingHint, 1)
 	assert.Equal(ndn.Nonce{0xA0, 0xA1, 0xA2, 0xA3}, interest.Nonce)
 	assert.Equal(30369*time.Millisecond, interest.Lifetime)
-	assert.EqualValues(220, interest.HopLimit)
+	if assert.NotNil(interest.HopLimit) {
+		assert.EqualValues(220, *interest.HopLimit)
+	}
+
+	assert.NoError(tlv.Decode(bytesFromHex("050E name=0703080141 nonce=0A04A0A1A2A3 hoplimit=220100"), &pkt))
+	interest = pkt.Interest
+	assert.NotNil(interest)
+	if assert.NotNil(interest.HopLimit) {
+		assert.EqualValues(0, *interest.HopLimit)
+	}
 }
@@ -0,0 +1,244 @@
+package keychain
+
+import (
+	"crypto"
+	"crypto/x509"
+	"errors"
+	"time"
+
+	"github.com/eric135/go-ndn"
+	"github.com/eric135/go-ndn/an"
+	"github.com/eric135/go-ndn/tlv"
+)
+
+// Error conditions for certificates.
+var (
+	ErrCertificate    = errors.New("bad certificate")
+	ErrValidityPeriod = ndn.ErrValidityPeriod
+	ErrPublicKey      = errors.New("bad SubjectPublicKeyInfo")
+	ErrExportKey      = errors.New("public key cannot be exported")
+)
+
+// DescriptionEntry is an entry in certificate AdditionalDescription.
+type DescriptionEntry struct {
+	Key   string
+	Value string
+}
+
+// Certificate represents an NDN certificate.
+// See https://named-data.net/doc/ndn-cxx/0.7.0/specs/certificate-format.html for format definition.
+type Certificate struct {
+	data        ndn.Data
+	publicKey   crypto.PublicKey
+	validity    ndn.ValidityPeriod
+	description []DescriptionEntry
+}
+
+// NewCertificate decodes a certificate from a Data packet.
+func NewCertificate(data ndn.Data) (cert *Certificate, e error) {
+	if !IsCertificate(data) || data.SigInfo == nil {
+		return nil, ErrCertificate
+	}
+	cert = &Certificate{data: data}
+	if cert.publicKey, e = ParsePublicKey(data.Content); e != nil {
+		return nil, e
+	}
+
+	if cert.validity, e = data.SigInfo.ValidityPeriod(); e != nil {
+		return nil, e
+	}
+	for _, ext := range data.SigInfo.Extensions {
+		if ext.Type == an.TtAdditionalDescription {
+			if cert.description, e = decodeAdditionalDescription(ext.Value); e != nil {
+				return nil, e
+			}
+		}
+	}
+	return cert, nil
+}
+
+// Data returns the certificate Data packet.
+func (cert Certificate) Data() ndn.Data {
+	return cert.data
+}
+
+// Name returns the certificate name.
+func (cert Certificate) Name() ndn.Name {
+	return cert.data.Name
+}
+
+// SubjectName returns the subject name.
+func (cert Certificate) SubjectName() ndn.Name {
+	return ToSubjectName(cert.data.Name)
+}
+
+// KeyName returns the key name.
+func (cert Certificate) KeyName() ndn.Name {
+	return ToKeyName(cert.data.Name)
+}
+
+// IssuerID returns the issuer ID component.
+func (cert Certificate) IssuerID() ndn.NameComponent {
+	return cert.data.Name.Get(-2)
+}
+
+// IssuerName returns the KeyLocator name, which is the key name or certificate name of the issuer.
+func (cert Certificate) IssuerName() ndn.Name {
+	return cert.data.SigInfo.KeyLocator.Name
+}
+
+// IsSelfSigned determines whether the certificate is signed by its own key.
+func (cert Certificate) IsSelfSigned() bool {
+	return ToKeyName(cert.IssuerName()).Equal(cert.KeyName())
+}
+
+// PublicKey returns the public key, such as *ecdsa.PublicKey or *rsa.PublicKey.
+func (cert Certificate) PublicKey() crypto.PublicKey {
+	return cert.publicKey
+}
+
+// ValidityPeriod returns the validity period.
+func (cert Certificate) ValidityPeriod() ndn.ValidityPeriod {
+	return cert.validity
+}
+
+// IsValidAt determines whether t is within the validity period.
+func (cert Certificate) IsValidAt(t time.Time) bool {
+	return cert.validity.Includes(t)
+}
+
+// AdditionalDescription returns AdditionalDescription entries.
+func (cert Certificate) AdditionalDescription() []DescriptionEntry {
+	return cert.description
+}
+
+// CertificateOptions contains arguments to MakeCertificate function.
+type CertificateOptions struct {
+	// PublicKey is the public key to be certified.
+	// It must implement PublicKeyExporter.
+	PublicKey PublicKey
+
+	// Signer is the issuer private key.
+	Signer PrivateKey
+
+	// IssuerID is the issuer ID component.
+	// The default is ComponentSelfIssuer if the certificate is self-signed, or ComponentDefaultIssuer otherwise.
+	IssuerID ndn.NameComponent
+
+	// Version is the version number.
+	// The default is current Unix timestamp in microseconds.
+	Version uint64
+
+	// ValidityPeriod is the validity period.
+	// The default starts now and lasts for DefaultCertificateValidity.
+	// If only NotAfter is set, NotBefore defaults to now.
+	ValidityPeriod ndn.ValidityPeriod
+
+	// Freshness is the FreshnessPeriod of the certificate.
+	// The default is DefaultCertificateFreshness.
+	Freshness time.Duration
+
+	// AdditionalDescription contains AdditionalDescription entries.
+	AdditionalDescription []DescriptionEntry
+}
+
+func (opts *CertificateOptions) applyDefaults() {
+	if !opts.IssuerID.Valid() {
+		if opts.Signer.Name().Equal(opts.PublicKey.Name()) {
+			opts.IssuerID = ComponentSelfIssuer
+		} else {
+			opts.IssuerID = ComponentDefaultIssuer
+		}
+	}
+	if opts.Version == 0 {
+		opts.Version = uint64(time.Now().UnixNano() / int64(time.Microsecond))
+	}
+	if opts.ValidityPeriod.NotBefore.IsZero() {
+		opts.ValidityPeriod.NotBefore = time.Now()
+	}
+	if opts.ValidityPeriod.NotAfter.IsZero() {
+		opts.ValidityPeriod.NotAfter = opts.ValidityPeriod.NotBefore.Add(DefaultCertificateValidity)
+	}
+	if opts.Freshness <= 0 {
+		opts.Freshness = DefaultCertificateFreshness
+	}
+}
+
+// CertificateOptions defaults.
+const (
+	DefaultCertificateValidity  = 365 * 24 * time.Hour
+	DefaultCertificateFreshness = time.Hour
+)
+
+// MakeCertificate creates and signs a certificate.
+func MakeCertificate(opts CertificateOptions) (cert *Certificate, e error) {
+	exporter, ok := opts.PublicKey.(PublicKeyExporter)
+	if !ok {
+		return nil, ErrExportKey
+	}
+	if !IsKeyName(opts.PublicKey.Name()) {
+		return nil, ErrKeyName
+	}
+	opts.applyDefaults()
+
+	spki, e := x509.MarshalPKIXPublicKey(exporter.CryptoPublicKey())
+	if e != nil {
+		return nil, e
+	}
+
+	name := append(append(ndn.Name{}, opts.PublicKey.Name()...), opts.IssuerID, ndn.MakeVersionComponent(opts.Version))
+	data := ndn.MakeData(name, ndn.ContentType(an.ContentKey), opts.Freshness, spki)
+
+	data.SigInfo = &ndn.SigInfo{}
+	if e := data.SigInfo.SetValidityPeriod(opts.ValidityPeriod); e != nil {
+		return nil, e
+	}
+	if len(opts.AdditionalDescription) > 0 {
+		description, e := encodeAdditionalDescription(opts.AdditionalDescription)
+		if e != nil {
+			return nil, e
+		}
+		data.SigInfo.Extensions = append(data.SigInfo.Extensions, description)
+	}
+
+	if e := opts.Signer.Sign(&data); e != nil {
+		return nil, e
+	}
+	return NewCertificate(data)
+}
+
+func decodeAdditionalDescription(wire []byte) (entries []DescriptionEntry, e error) {
+	d := tlv.Decoder(wire)
+	for _, field := range d.Elements() {
+		if field.Type != an.TtDescriptionEntry {
+			return nil, ErrCertificate
+		}
+		d1 := tlv.Decoder(field.Value)
+		fields1 := d1.Elements()
+		if e := d1.ErrUnlessEOF(); e != nil || len(fields1) != 2 ||
+			fields1[0].Type != an.TtDescriptionKey || fields1[1].Type != an.TtDescriptionValue {
+			return nil, ErrCertificate
+		}
+		entries = append(entries, DescriptionEntry{
+			Key:   string(fields1[0].Value),
+			Value: string(fields1[1].Value),
+		})
+	}
+	return entries, d.ErrUnlessEOF()
+}
+
+func encodeAdditionalDescription(entries []DescriptionEntry) (tlv.Element, error) {
+	var fields []interface{}
+	for _, entry := range entries {
+		value, e := tlv.Encode(
+			tlv.MakeElement(an.TtDescriptionKey, []byte(entry.Key)),
+			tlv.MakeElement(an.TtDescriptionValue, []byte(entry.Value)),
+		)
+		if e != nil {
+			return tlv.Element{}, e
+		}
+		fields = append(fields, tlv.MakeElement(an.TtDescriptionEntry, value))
+	}
+	value, e := tlv.Encode(fields...)
+	return tlv.MakeElement(an.TtAdditionalDescription, value), e
+}
@@ -0,0 +1,120 @@
+package keychain_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+	"time"
+
+	"github.com/eric135/go-ndn"
+	"github.com/eric135/go-ndn/keychain"
+	"github.com/eric135/go-ndn/keychain/eckey"
+	"github.com/eric135/go-ndn/ndntestvector"
+	"github.com/eric135/go-ndn/tlv"
+)
+
+func TestCertificateTestbed(t *testing.T) {
+	assert, require := makeAR(t)
+
+	root, e := keychain.NewCertificate(ndntestvector.TestbedRootV2())
+	require.NoError(e)
+	nameEqual(assert, "/ndn", root.SubjectName())
+	nameEqual(assert, "/ndn/KEY/%65%9D%7F%A5%C5%81%10%7D", root.KeyName())
+	assert.Equal("8=ndn", root.IssuerID().String())
+	assert.True(root.IsSelfSigned())
+	assert.IsType(&ecdsa.PublicKey{}, root.PublicKey())
+	validity := root.ValidityPeriod()
+	assert.Equal(time.Date(2017, 12, 20, 0, 19, 39, 0, time.UTC), validity.NotBefore)
+	assert.Equal(time.Date(2020, 12, 31, 23, 59, 59, 0, time.UTC), validity.NotAfter)
+	assert.True(root.IsValidAt(time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)))
+	assert.False(root.IsValidAt(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)))
+	assert.Equal([]keychain.DescriptionEntry{{Key: "fullname", Value: "NDN Testbed Root"}}, root.AdditionalDescription())
+
+	arizona, e := keychain.NewCertificate(ndntestvector.TestbedArizona20200301())
+	require.NoError(e)
+	nameEqual(assert, "/ndn/edu/arizona", arizona.SubjectName())
+	assert.False(arizona.IsSelfSigned())
+	nameEqual(assert, root.KeyName(), arizona.IssuerName())
+	assert.IsType(&rsa.PublicKey{}, arizona.PublicKey())
+	assert.Len(arizona.AdditionalDescription(), 6)
+
+	rootPub, e := eckey.NewPublicKey(root.KeyName(), root.PublicKey().(*ecdsa.PublicKey))
+	require.NoError(e)
+	assert.NoError(rootPub.Verify(root.Data()))
+	assert.NoError(rootPub.Verify(arizona.Data()))
+
+	shijunxiao, e := keychain.NewCertificate(ndntestvector.TestbedShijunxiao20200301())
+	require.NoError(e)
+	nameEqual(assert, arizona.KeyName(), shijunxiao.IssuerName())
+
+	_, e = keychain.NewCertificate(ndn.MakeData("/ndn/KEY/key-id/issuer-id/version"))
+	assert.Error(e)
+}
+
+func TestMakeCertificate(t *testing.T) {
+	assert, require := makeAR(t)
+
+	makeKey := func(subjectName string) (keychain.PrivateKey, keychain.PublicKey) {
+		keyName := keychain.ToKeyName(ndn.ParseName(subjectName))
+		key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		pvt, e := eckey.NewPrivateKey(keyName, key)
+		require.NoError(e)
+		pub, e := eckey.NewPublicKey(keyName, &key.PublicKey)
+		require.NoError(e)
+		return pvt, pub
+	}
+	pvtA, pubA := makeKey("/A")
+	pvtB, pubB := makeKey("/B")
+
+	certA, e := keychain.MakeCertificate(keychain.CertificateOptions{
+		PublicKey: pubA,
+		Signer:    pvtA,
+	})
+	require.NoError(e)
+	assert.True(certA.IsSelfSigned())
+	assert.True(certA.IssuerID().Equal(keychain.ComponentSelfIssuer))
+	assert.True(certA.IsValidAt(time.Now()))
+	assert.False(certA.IsValidAt(time.Now().Add(keychain.DefaultCertificateValidity + time.Hour)))
+
+	validity := ndn.ValidityPeriod{
+		NotBefore: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		NotAfter:  time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	certB, e := keychain.MakeCertificate(keychain.CertificateOptions{
+		PublicKey:             pubB,
+		Signer:                pvtA,
+		Version:               5,
+		ValidityPeriod:        validity,
+		AdditionalDescription: []keychain.DescriptionEntry{{Key: "k", Value: "v"}},
+	})
+	require.NoError(e)
+	assert.Equal("v=5", certB.Name().Get(-1).String())
+	assert.True(certB.IssuerID().Equal(keychain.ComponentDefaultIssuer))
+	nameEqual(assert, pubA.Name(), certB.IssuerName())
+	assert.NoError(pubA.Verify(certB.Data()))
+	assert.Error(pubB.Verify(certB.Data()))
+
+	// round-trip through wire encoding
+	wire, e := tlv.Encode(certB.Data())
+	require.NoError(e)
+	var pkt ndn.Packet
+	require.NoError(tlv.Decode(wire, &pkt))
+	decoded, e := keychain.NewCertificate(*pkt.Data)
+	require.NoError(e)
+	nameEqual(assert, certB.Name(), decoded.Name())
+	assert.Equal(validity, decoded.ValidityPeriod())
+	assert.Equal([]keychain.DescriptionEntry{{Key: "k", Value: "v"}}, decoded.AdditionalDescription())
+	assert.Equal(pubB.(keychain.PublicKeyExporter).CryptoPublicKey(), decoded.PublicKey())
+	assert.NoError(pubA.Verify(decoded.Data()))
+
+	certB2, e := keychain.MakeCertificate(keychain.CertificateOptions{
+		PublicKey: pubB,
+		Signer:    pvtB,
+		IssuerID:  ndn.ParseNameComponent("issuer"),
+	})
+	require.NoError(e)
+	assert.True(certB2.IsSelfSigned())
+	assert.Equal("8=issuer", certB2.IssuerID().String())
+}
@@ -2,6 +2,7 @@
 package eckey
 
 import (
+	"crypto"
 	"crypto/ecdsa"
 	"crypto/rand"
 	"crypto/sha256"
@@ -71,6 +72,10 @@ func (pub *publicKey) Name() ndn.Name {
 	return pub.name
 }
 
+func (pub *publicKey) CryptoPublicKey() crypto.PublicKey {
+	return pub.key
+}
+
 func (pub *publicKey) Verify(packet ndn.Verifiable) error {
 	return packet.VerifyWith(func(name ndn.Name, si ndn.SigInfo) (ndn.LLVerify, error) {
 		if si.Type != an.SignatureSha256WithEcdsa {
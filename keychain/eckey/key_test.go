@@ -11,6 +11,7 @@ import (
 	"github.com/eric135/go-ndn/keychain"
 	"github.com/eric135/go-ndn/keychain/eckey"
 	"github.com/eric135/go-ndn/ndntestenv"
+	"github.com/eric135/go-ndn/ndntestvector"
 )
 
 func TestSigning(t *testing.T) {
@@ -56,6 +57,18 @@ func TestSigning(t *testing.T) {
 	nameEqual(assert, certNameB, dataB.SigInfo.KeyLocator)
 }
 
-// TestVerify test case is absent due to lack of test vector.
-// ndntestvector.TestbedRootV2() uses "specific curve" format that is unsupported by Go crypto/x509 library.
-// See https://redmine.named-data.net/issues/5037
+func TestVerify(t *testing.T) {
+	assert, require := makeAR(t)
+	cert := ndntestvector.TestbedRootV2()
+	data := ndntestvector.TestbedArizona20200301()
+
+	// TestbedRootV2 uses "specific curve" format that is unsupported by Go crypto/x509 library.
+	// See https://redmine.named-data.net/issues/5037
+	ecdsaPublicKey, e := keychain.ParsePublicKey(cert.Content)
+	require.NoError(e)
+	pub, e := eckey.NewPublicKey(keychain.ToKeyName(cert.Name), ecdsaPublicKey.(*ecdsa.PublicKey))
+	require.NoError(e)
+
+	assert.NoError(pub.Verify(cert))
+	assert.NoError(pub.Verify(data))
+}
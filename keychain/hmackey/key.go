@@ -0,0 +1,75 @@
+// Package hmackey implements SigHmacWithSha256 signature type.
+package hmackey
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+
+	"github.com/eric135/go-ndn"
+	"github.com/eric135/go-ndn/an"
+	"github.com/eric135/go-ndn/keychain"
+)
+
+// Error conditions.
+var (
+	ErrSecret = errors.New("HMAC secret is empty")
+)
+
+// NewKey creates a private key and a public key for SigHmacWithSha256 signature type.
+// Both keys share the same secret, which is copied.
+// Signed packets have KeyLocator set to the key name.
+func NewKey(name ndn.Name, secret []byte) (keychain.PrivateKey, keychain.PublicKey, error) {
+	if !keychain.IsKeyName(name) {
+		return nil, nil, keychain.ErrKeyName
+	}
+	if len(secret) == 0 {
+		return nil, nil, ErrSecret
+	}
+	k := &key{
+		name:   name,
+		secret: append([]byte{}, secret...),
+	}
+	return k, k, nil
+}
+
+type key struct {
+	name   ndn.Name
+	secret []byte
+}
+
+func (k *key) Name() ndn.Name {
+	return k.name
+}
+
+func (k *key) Sign(packet ndn.Signable) error {
+	return packet.SignWith(func(name ndn.Name, si *ndn.SigInfo) (ndn.LLSign, error) {
+		si.Type = an.SignatureHmacWithSha256
+		si.KeyLocator = ndn.KeyLocator{
+			Name: k.name,
+		}
+		return func(input []byte) (sig []byte, e error) {
+			return k.compute(input), nil
+		}, nil
+	})
+}
+
+func (k *key) Verify(packet ndn.Verifiable) error {
+	return packet.VerifyWith(func(name ndn.Name, si ndn.SigInfo) (ndn.LLVerify, error) {
+		if si.Type != an.SignatureHmacWithSha256 {
+			return nil, ndn.ErrSigType
+		}
+		return func(input, sig []byte) error {
+			if !hmac.Equal(sig, k.compute(input)) {
+				return ndn.ErrSigValue
+			}
+			return nil
+		}, nil
+	})
+}
+
+func (k *key) compute(input []byte) []byte {
+	h := hmac.New(sha256.New, k.secret)
+	h.Write(input)
+	return h.Sum(nil)
+}
@@ -0,0 +1,50 @@
+package hmackey_test
+
+import (
+	"testing"
+
+	"github.com/eric135/go-ndn"
+	"github.com/eric135/go-ndn/an"
+	"github.com/eric135/go-ndn/keychain"
+	"github.com/eric135/go-ndn/keychain/hmackey"
+	"github.com/eric135/go-ndn/ndntestenv"
+)
+
+func TestSigning(t *testing.T) {
+	assert, require := makeAR(t)
+
+	subjectName := ndn.ParseName("/K")
+	_, _, e := hmackey.NewKey(subjectName, []byte("secret-A"))
+	assert.Error(e)
+
+	keyNameA := keychain.ToKeyName(subjectName)
+	_, _, e = hmackey.NewKey(keyNameA, nil)
+	assert.Error(e)
+
+	secretA := []byte("secret-A")
+	pvtA, pubA, e := hmackey.NewKey(keyNameA, secretA)
+	require.NoError(e)
+	nameEqual(assert, keyNameA, pvtA)
+	nameEqual(assert, keyNameA, pubA)
+	secretA[0] = 'X' // NewKey copies the secret
+
+	keyNameB := keychain.ToKeyName(subjectName)
+	pvtB, pubB, e := hmackey.NewKey(keyNameB, []byte("secret-B"))
+	require.NoError(e)
+
+	var c ndntestenv.SignVerifyTester
+	c.PvtA, c.PvtB, c.PubA, c.PubB = pvtA, pvtB, pubA, pubB
+	c.CheckInterest(t)
+	c.CheckInterestParameterized(t)
+	rec := c.CheckData(t)
+
+	dataA := rec.PktA.(*ndn.Data)
+	assert.EqualValues(an.SignatureHmacWithSha256, dataA.SigInfo.Type)
+	nameEqual(assert, keyNameA, dataA.SigInfo.KeyLocator)
+	assert.Len(dataA.SigValue, 32)
+
+	assert.Error(ndn.DigestSigning.Verify(dataA))
+	dataD := ndn.MakeData("/D")
+	require.NoError(ndn.DigestSigning.Sign(&dataD))
+	assert.Error(pubA.Verify(dataD))
+}
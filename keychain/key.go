@@ -2,6 +2,8 @@
 package keychain
 
 import (
+	"crypto"
+
 	"github.com/eric135/go-ndn"
 	"github.com/eric135/go-ndn/an"
 )
@@ -27,6 +29,15 @@ type PublicKey interface {
 	Name() ndn.Name
 }
 
+// PublicKeyExporter is a PublicKey whose key material can be exported.
+// This is needed for issuing a certificate of the key.
+type PublicKeyExporter interface {
+	PublicKey
+
+	// CryptoPublicKey returns the underlying public key, such as *ecdsa.PublicKey or *rsa.PublicKey.
+	CryptoPublicKey() crypto.PublicKey
+}
+
 func init() {
-	ndn.RegisterSigInfoExtension(an.TtValidityPeriod)
+	ndn.RegisterSigInfoExtension(an.TtAdditionalDescription)
 }
@@ -7,7 +7,6 @@ import (
 
 	"github.com/eric135/go-ndn"
 	"github.com/eric135/go-ndn/an"
-	"github.com/eric135/go-ndn/tlv"
 )
 
 // Name components for certificate naming.
@@ -86,7 +85,6 @@ func makeRandomKeyID() ndn.NameComponent {
 	return ndn.MakeNameComponent(an.TtGenericNameComponent, value)
 }
 
-func makeVersionFromCurrentTime() (comp ndn.NameComponent) {
-	comp.Element = tlv.MakeElementNNI(an.TtVersionNameComponent, time.Now().UnixNano()/int64(time.Microsecond/time.Nanosecond))
-	return
+func makeVersionFromCurrentTime() ndn.NameComponent {
+	return ndn.MakeVersionComponent(uint64(time.Now().UnixNano() / int64(time.Microsecond/time.Nanosecond)))
 }
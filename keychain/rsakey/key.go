@@ -71,6 +71,10 @@ func (pub *publicKey) Name() ndn.Name {
 	return pub.name
 }
 
+func (pub *publicKey) CryptoPublicKey() crypto.PublicKey {
+	return pub.key
+}
+
 func (pub *publicKey) Verify(packet ndn.Verifiable) error {
 	return packet.VerifyWith(func(name ndn.Name, si ndn.SigInfo) (ndn.LLVerify, error) {
 		if si.Type != an.SignatureSha256WithRsa {
@@ -0,0 +1,77 @@
+package keychain
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"math/big"
+)
+
+var oidPublicKeyECDSA = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+
+type subjectPublicKeyInfo struct {
+	Algorithm pkix.AlgorithmIdentifier
+	PublicKey asn1.BitString
+}
+
+// ecSpecifiedDomain is the ECParameters structure with explicit curve parameters (RFC 3279).
+type ecSpecifiedDomain struct {
+	Version int
+	FieldID struct {
+		FieldType asn1.ObjectIdentifier
+		Prime     *big.Int
+	}
+	Curve struct {
+		A    []byte
+		B    []byte
+		Seed asn1.BitString `asn1:"optional"`
+	}
+	Base     []byte
+	Order    *big.Int
+	Cofactor int `asn1:"optional"`
+}
+
+// ParsePublicKey parses a public key in SubjectPublicKeyInfo format.
+// Unlike x509.ParsePKIXPublicKey, this also accepts EC public keys with explicit curve parameters,
+// as long as the parameters match a named curve.
+func ParsePublicKey(spki []byte) (crypto.PublicKey, error) {
+	if key, e := x509.ParsePKIXPublicKey(spki); e == nil {
+		return key, nil
+	}
+
+	var info subjectPublicKeyInfo
+	if rest, e := asn1.Unmarshal(spki, &info); e != nil || len(rest) > 0 {
+		return nil, ErrPublicKey
+	}
+	if !info.Algorithm.Algorithm.Equal(oidPublicKeyECDSA) {
+		return nil, ErrPublicKey
+	}
+
+	var domain ecSpecifiedDomain
+	if rest, e := asn1.Unmarshal(info.Algorithm.Parameters.FullBytes, &domain); e != nil || len(rest) > 0 {
+		return nil, ErrPublicKey
+	}
+	for _, curve := range []elliptic.Curve{elliptic.P224(), elliptic.P256(), elliptic.P384(), elliptic.P521()} {
+		params := curve.Params()
+		if params.P.Cmp(domain.FieldID.Prime) != 0 || params.N.Cmp(domain.Order) != 0 ||
+			params.B.Cmp(new(big.Int).SetBytes(domain.Curve.B)) != 0 {
+			continue
+		}
+		// named curves have A = P-3
+		a := new(big.Int).Sub(params.P, big.NewInt(3))
+		if a.Cmp(new(big.Int).SetBytes(domain.Curve.A)) != 0 ||
+			!bytes.Equal(domain.Base, elliptic.Marshal(curve, params.Gx, params.Gy)) {
+			return nil, ErrPublicKey
+		}
+		x, y := elliptic.Unmarshal(curve, info.PublicKey.RightAlign())
+		if x == nil {
+			return nil, ErrPublicKey
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+	return nil, ErrPublicKey
+}
@@ -0,0 +1,34 @@
+package keychain_test
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"math/big"
+	"testing"
+
+	"github.com/eric135/go-ndn/keychain"
+	"github.com/eric135/go-ndn/ndntestvector"
+)
+
+func TestParsePublicKeySpecifiedCurve(t *testing.T) {
+	assert, require := makeAR(t)
+
+	// TestbedRootV2 has a P-256 key with explicit curve parameters.
+	spki := ndntestvector.TestbedRootV2().Content
+	key, e := keychain.ParsePublicKey(spki)
+	require.NoError(e)
+	require.IsType(&ecdsa.PublicKey{}, key)
+	assert.Equal(elliptic.P256(), key.(*ecdsa.PublicKey).Curve)
+
+	params := elliptic.P256().Params()
+	a := new(big.Int).Sub(params.P, big.NewInt(3)).Bytes()
+	for _, field := range [][]byte{params.Gx.Bytes(), a} {
+		pos := bytes.Index(spki, field)
+		require.GreaterOrEqual(pos, 0)
+		modified := append([]byte{}, spki...)
+		modified[pos+len(field)-1] ^= 0x01
+		_, e = keychain.ParsePublicKey(modified)
+		assert.Error(e)
+	}
+}
@@ -0,0 +1,173 @@
+package l3
+
+import (
+	"container/list"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/eric135/go-ndn"
+	"github.com/eric135/go-ndn/an"
+	"github.com/eric135/go-ndn/tlv"
+)
+
+// ContentStore represents a cache of Data packets.
+type ContentStore interface {
+	// Insert adds a Data packet.
+	// If a Data packet with the same name exists, it is replaced.
+	Insert(data ndn.Data)
+
+	// Find returns a Data packet that can satisfy the Interest, or nil if there is none.
+	Find(interest ndn.Interest) *ndn.Data
+}
+
+// LruContentStoreConfig contains LRU ContentStore configuration.
+type LruContentStoreConfig struct {
+	// Capacity is the maximum number of cached Data packets.
+	// The default is DefaultCsCapacity.
+	Capacity int
+
+	// MaxBytes is the maximum total encoded size of cached Data packets.
+	// The default is DefaultCsMaxBytes.
+	MaxBytes int
+}
+
+func (cfg *LruContentStoreConfig) applyDefaults() {
+	if cfg.Capacity <= 0 {
+		cfg.Capacity = DefaultCsCapacity
+	}
+	if cfg.MaxBytes <= 0 {
+		cfg.MaxBytes = DefaultCsMaxBytes
+	}
+}
+
+// LruContentStoreConfig defaults.
+const (
+	DefaultCsCapacity = 1024
+	DefaultCsMaxBytes = 1 << 24
+)
+
+// NewLruContentStore creates a ContentStore with least-recently-used replacement policy.
+//
+// MustBeFresh is evaluated with the FreshnessPeriod of the Data, counted from the time it is inserted.
+// Lookups with CanBePrefix use a name index sorted in canonical order.
+// The returned ContentStore is thread-safe.
+func NewLruContentStore(cfg LruContentStoreConfig) ContentStore {
+	cfg.applyDefaults()
+	return &lruCs{
+		cfg:    cfg,
+		byName: make(map[string]*csEntry),
+		lru:    list.New(),
+	}
+}
+
+type csEntry struct {
+	key     string
+	data    ndn.Data
+	size    int
+	arrival time.Time
+	elem    *list.Element
+}
+
+func (entry *csEntry) canSatisfy(interest ndn.Interest, now time.Time) bool {
+	if interest.MustBeFresh && !now.Before(entry.arrival.Add(entry.data.Freshness)) {
+		return false
+	}
+	return entry.data.CanSatisfy(interest)
+}
+
+type lruCs struct {
+	cfg    LruContentStoreConfig
+	mutex  sync.Mutex
+	byName map[string]*csEntry
+	sorted []*csEntry // ordered by name
+	lru    *list.List // *csEntry, most recently used at front
+	nBytes int
+}
+
+func (cs *lruCs) Insert(data ndn.Data) {
+	wire, e := tlv.Encode(data)
+	if e != nil || len(wire) > cs.cfg.MaxBytes {
+		return
+	}
+
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+
+	nameV, _ := data.Name.MarshalBinary()
+	key := string(nameV)
+	if old := cs.byName[key]; old != nil {
+		cs.erase(old)
+	}
+
+	entry := &csEntry{
+		key:     key,
+		data:    data,
+		size:    len(wire),
+		arrival: time.Now(),
+	}
+	entry.elem = cs.lru.PushFront(entry)
+	cs.byName[key] = entry
+	i := cs.search(data.Name)
+	cs.sorted = append(cs.sorted, nil)
+	copy(cs.sorted[i+1:], cs.sorted[i:])
+	cs.sorted[i] = entry
+	cs.nBytes += entry.size
+
+	for len(cs.byName) > cs.cfg.Capacity || cs.nBytes > cs.cfg.MaxBytes {
+		cs.erase(cs.lru.Back().Value.(*csEntry))
+	}
+}
+
+func (cs *lruCs) Find(interest ndn.Interest) *ndn.Data {
+	nComps := len(interest.Name)
+	if nComps == 0 {
+		return nil
+	}
+
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+
+	now := time.Now()
+	var found *csEntry
+	switch {
+	case interest.Name[nComps-1].Type == an.TtImplicitSha256DigestComponent:
+		found = cs.findExact(interest.Name.GetPrefix(nComps - 1))
+	case !interest.CanBePrefix:
+		found = cs.findExact(interest.Name)
+	default:
+		for i := cs.search(interest.Name); i < len(cs.sorted) && interest.Name.IsPrefixOf(cs.sorted[i].data.Name); i++ {
+			if entry := cs.sorted[i]; entry.canSatisfy(interest, now) {
+				found = entry
+				break
+			}
+		}
+	}
+
+	if found == nil || !found.canSatisfy(interest, now) {
+		return nil
+	}
+	cs.lru.MoveToFront(found.elem)
+	data := found.data
+	return &data
+}
+
+func (cs *lruCs) findExact(name ndn.Name) *csEntry {
+	nameV, _ := name.MarshalBinary()
+	return cs.byName[string(nameV)]
+}
+
+// search returns the index of the first entry whose name is not less than name.
+func (cs *lruCs) search(name ndn.Name) int {
+	return sort.Search(len(cs.sorted), func(i int) bool {
+		return cs.sorted[i].data.Name.Compare(name) >= 0
+	})
+}
+
+func (cs *lruCs) erase(entry *csEntry) {
+	cs.lru.Remove(entry.elem)
+	delete(cs.byName, entry.key)
+	i := cs.search(entry.data.Name)
+	cs.sorted = append(cs.sorted[:i], cs.sorted[i+1:]...)
+	cs.nBytes -= entry.size
+}
@@ -0,0 +1,116 @@
+package l3_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/eric135/go-ndn"
+	"github.com/eric135/go-ndn/an"
+	"github.com/eric135/go-ndn/l3"
+)
+
+func TestContentStoreFind(t *testing.T) {
+	assert, require := makeAR(t)
+
+	cs := l3.NewLruContentStore(l3.LruContentStoreConfig{})
+	cs.Insert(ndn.MakeData("/A/1", 100*time.Millisecond))
+	cs.Insert(ndn.MakeData("/A/2"))
+	cs.Insert(ndn.MakeData("/B/1"))
+	dataC := ndn.MakeData("/C/1", []byte{0xC0})
+	cs.Insert(dataC)
+
+	data := cs.Find(ndn.MakeInterest("/A/2"))
+	require.NotNil(data)
+	nameEqual(assert, "/A/2", data)
+	assert.Nil(cs.Find(ndn.MakeInterest("/A")))
+	assert.Nil(cs.Find(ndn.MakeInterest("/A/3")))
+
+	data = cs.Find(ndn.MakeInterest("/A", ndn.CanBePrefixFlag))
+	require.NotNil(data)
+	nameEqual(assert, "/A/1", data)
+	data = cs.Find(ndn.MakeInterest("/A", ndn.CanBePrefixFlag, ndn.MustBeFreshFlag))
+	require.NotNil(data)
+	nameEqual(assert, "/A/1", data)
+	assert.Nil(cs.Find(ndn.MakeInterest("/A/2", ndn.MustBeFreshFlag)))
+
+	data = cs.Find(ndn.MakeInterest(dataC.FullName()))
+	require.NotNil(data)
+	nameEqual(assert, "/C/1", data)
+	fullName := ndn.MakeData("/C/1", []byte{0xC1}).FullName()
+	assert.Nil(cs.Find(ndn.MakeInterest(fullName)))
+
+	time.Sleep(150 * time.Millisecond)
+	assert.Nil(cs.Find(ndn.MakeInterest("/A", ndn.CanBePrefixFlag, ndn.MustBeFreshFlag)))
+	assert.NotNil(cs.Find(ndn.MakeInterest("/A", ndn.CanBePrefixFlag)))
+}
+
+func TestContentStoreEvict(t *testing.T) {
+	assert, _ := makeAR(t)
+
+	cs := l3.NewLruContentStore(l3.LruContentStoreConfig{Capacity: 2})
+	cs.Insert(ndn.MakeData("/A"))
+	cs.Insert(ndn.MakeData("/B"))
+	assert.NotNil(cs.Find(ndn.MakeInterest("/A")))
+	cs.Insert(ndn.MakeData("/C")) // evicts /B, the least recently used
+	assert.NotNil(cs.Find(ndn.MakeInterest("/A")))
+	assert.Nil(cs.Find(ndn.MakeInterest("/B")))
+	assert.NotNil(cs.Find(ndn.MakeInterest("/C")))
+
+	cs = l3.NewLruContentStore(l3.LruContentStoreConfig{MaxBytes: 1500})
+	cs.Insert(ndn.MakeData("/A", make([]byte, 1000)))
+	cs.Insert(ndn.MakeData("/B", make([]byte, 1000))) // evicts /A
+	cs.Insert(ndn.MakeData("/C", make([]byte, 2000))) // too large
+	assert.Nil(cs.Find(ndn.MakeInterest("/A")))
+	assert.NotNil(cs.Find(ndn.MakeInterest("/B")))
+	assert.Nil(cs.Find(ndn.MakeInterest("/C")))
+}
+
+func TestForwarderContentStore(t *testing.T) {
+	assert, require := makeAR(t)
+
+	fw := l3.NewForwarderWithConfig(l3.ForwarderConfig{
+		ContentStore: l3.NewLruContentStore(l3.LruContentStoreConfig{}),
+	})
+	_, faceA := addTestFace(require, fw)
+	defer close(faceA.Tx())
+	ffP, faceP := addTestFace(require, fw)
+	defer close(faceP.Tx())
+	ffP.AddRoute(ndn.ParseName("/"), 0)
+
+	for i, name := range []string{"/C", "/N"} {
+		faceA.Tx() <- ndn.MakeInterest(name, makeTokenLpL3([]byte{0xA0}))
+		pkt := recvPacket(faceP)
+		require.NotNil(pkt)
+		require.NotNil(pkt.Interest)
+
+		lph := makeTokenLpL3(pkt.Lp.PitToken)
+		if i == 1 {
+			lph.CachePolicyType = an.CachePolicyNoCache
+		}
+		faceP.Tx() <- ndn.MakeData(pkt.Interest, lph)
+		pkt = recvPacket(faceA)
+		require.NotNil(pkt)
+		require.NotNil(pkt.Data)
+	}
+
+	faceA.Tx() <- ndn.MakeInterest("/C", makeTokenLpL3([]byte{0xA1}))
+	pkt := recvPacket(faceA)
+	require.NotNil(pkt)
+	require.NotNil(pkt.Data)
+	nameEqual(assert, "/C", pkt.Data)
+	assert.Equal([]byte{0xA1}, pkt.Lp.PitToken)
+	assert.Nil(recvPacket(faceP))
+
+	faceA.Tx() <- ndn.MakeInterest("/N", makeTokenLpL3([]byte{0xA2}))
+	pkt = recvPacket(faceP)
+	require.NotNil(pkt)
+	require.NotNil(pkt.Interest)
+
+	// unsolicited Data is not cached
+	faceP.Tx() <- ndn.MakeData("/U", makeTokenLpL3([]byte{0xFF, 0xFF, 0x01, 0xA3}))
+	assert.Nil(recvPacket(faceA))
+	faceA.Tx() <- ndn.MakeInterest("/U", makeTokenLpL3([]byte{0xA4}))
+	pkt = recvPacket(faceP)
+	require.NotNil(pkt)
+	require.NotNil(pkt.Interest)
+}
@@ -0,0 +1,6 @@
+// Package ethernet implements Transports over Ethernet, using Linux AF_PACKET sockets.
+//
+// Each frame carries exactly one TLV element in the payload of an Ethernet frame with NDN EtherType.
+// Trailing padding of short frames is ignored.
+// Creating an AF_PACKET socket requires CAP_NET_RAW capability.
+package ethernet
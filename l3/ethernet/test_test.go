@@ -0,0 +1,9 @@
+package ethernet_test
+
+import (
+	"github.com/usnistgov/ndn-dpdk/core/testenv"
+)
+
+var (
+	makeAR = testenv.MakeAR
+)
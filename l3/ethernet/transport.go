@@ -0,0 +1,304 @@
+//go:build linux
+// +build linux
+
+package ethernet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"net"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"github.com/eric135/go-ndn/l3"
+	"github.com/eric135/go-ndn/tlv"
+)
+
+// EtherType is the NDN EtherType.
+const EtherType = 0x8624
+
+// Error conditions.
+var (
+	ErrAddress = errors.New("invalid Ethernet address")
+)
+
+// Config contains Ethernet transport configuration.
+type Config struct {
+	l3.TransportQueueConfig
+
+	// MTU is the maximum size of an outgoing frame payload.
+	// The default is the MTU of the network interface.
+	MTU int
+
+	// MulticastGroup is the multicast group address.
+	// This is effective only in NewMulticastTransport.
+	// The default is DefaultMulticastGroup.
+	MulticastGroup string
+}
+
+func (cfg *Config) applyDefaults(ifi *net.Interface) {
+	cfg.ApplyTransportQueueConfigDefaults()
+	if cfg.MTU <= 0 {
+		cfg.MTU = ifi.MTU
+	}
+	if cfg.MulticastGroup == "" {
+		cfg.MulticastGroup = DefaultMulticastGroup
+	}
+}
+
+// Config defaults.
+const (
+	DefaultMulticastGroup = "01:00:5e:00:17:aa"
+)
+
+// Transport is an l3.Transport that communicates over an AF_PACKET socket.
+//
+// If a socket error occurs, the transport enters TransportDown state.
+// It returns to TransportUp state after the next successful send or receive operation.
+// The transport closes itself after its TX channel has been closed.
+type Transport interface {
+	l3.Transport
+	l3.TransportMTU
+	l3.TransportMultiAccess
+
+	// Interface returns the network interface.
+	Interface() net.Interface
+
+	// Remote returns the remote address, which is either a unicast address or a multicast group.
+	Remote() net.HardwareAddr
+}
+
+// NewTransport creates an Ethernet unicast transport.
+// ifname is the network interface name.
+// remote is the MAC address of the peer.
+//
+// Incoming frames are accepted only if they are addressed to the local interface and sent by the peer.
+func NewTransport(ifname, remote string, cfg Config) (Transport, error) {
+	raddr, e := net.ParseMAC(remote)
+	if e != nil {
+		return nil, e
+	}
+	if len(raddr) != 6 || raddr[0]&0x01 != 0 {
+		return nil, ErrAddress
+	}
+	return newTransport(ifname, raddr, false, cfg)
+}
+
+// NewMulticastTransport creates an Ethernet multicast transport.
+// ifname is the network interface name.
+// The multicast group is specified in cfg.MulticastGroup, which defaults to the NDN multicast group.
+//
+// Incoming frames are accepted from any sender if they are addressed to the multicast group.
+func NewMulticastTransport(ifname string, cfg Config) (Transport, error) {
+	if cfg.MulticastGroup == "" {
+		cfg.MulticastGroup = DefaultMulticastGroup
+	}
+	group, e := net.ParseMAC(cfg.MulticastGroup)
+	if e != nil {
+		return nil, e
+	}
+	if len(group) != 6 || group[0]&0x01 == 0 {
+		return nil, ErrAddress
+	}
+	return newTransport(ifname, group, true, cfg)
+}
+
+type transport struct {
+	*l3.TransportBase
+	p         *l3.TransportBasePriv
+	cfg       Config
+	ifi       net.Interface
+	remote    net.HardwareAddr
+	multicast bool
+	file      *os.File
+	conn      syscall.RawConn
+	dest      syscall.SockaddrLinklayer
+	header    []byte // Ethernet header of outgoing frames
+	closed    int32  // atomic bool
+}
+
+func newTransport(ifname string, remote net.HardwareAddr, multicast bool, cfg Config) (tr *transport, e error) {
+	ifi, e := net.InterfaceByName(ifname)
+	if e != nil {
+		return nil, e
+	}
+	cfg.applyDefaults(ifi)
+
+	fd, e := syscall.Socket(syscall.AF_PACKET, syscall.SOCK_RAW|syscall.SOCK_NONBLOCK|syscall.SOCK_CLOEXEC, int(htons(EtherType)))
+	if e != nil {
+		return nil, os.NewSyscallError("socket", e)
+	}
+	tr = &transport{
+		cfg:       cfg,
+		ifi:       *ifi,
+		remote:    remote,
+		multicast: multicast,
+		dest: syscall.SockaddrLinklayer{
+			Protocol: htons(EtherType),
+			Ifindex:  ifi.Index,
+			Halen:    6,
+		},
+	}
+	copy(tr.dest.Addr[:], remote)
+	tr.header = make([]byte, ethHeaderSize)
+	copy(tr.header[0:], remote)
+	copy(tr.header[6:], ifi.HardwareAddr)
+	binary.BigEndian.PutUint16(tr.header[12:], EtherType)
+
+	if e = syscall.Bind(fd, &syscall.SockaddrLinklayer{Protocol: htons(EtherType), Ifindex: ifi.Index}); e != nil {
+		syscall.Close(fd)
+		return nil, os.NewSyscallError("bind", e)
+	}
+	if multicast {
+		if e = joinMulticast(fd, ifi.Index, remote); e != nil {
+			syscall.Close(fd)
+			return nil, e
+		}
+	}
+
+	tr.file = os.NewFile(uintptr(fd), "AF_PACKET "+ifname)
+	if tr.conn, e = tr.file.SyscallConn(); e != nil {
+		tr.file.Close()
+		return nil, e
+	}
+
+	tr.TransportBase, tr.p = l3.NewTransportBase(cfg.TransportQueueConfig)
+	go tr.rxLoop()
+	go tr.txLoop()
+	return tr, nil
+}
+
+func (tr *transport) MTU() int {
+	return tr.cfg.MTU
+}
+
+func (tr *transport) Interface() net.Interface {
+	return tr.ifi
+}
+
+func (tr *transport) Remote() net.HardwareAddr {
+	return tr.remote
+}
+
+// IsMultiAccess implements l3.TransportMultiAccess.
+func (tr *transport) IsMultiAccess() bool {
+	return tr.multicast
+}
+
+func (tr *transport) isClosed() bool {
+	return atomic.LoadInt32(&tr.closed) != 0
+}
+
+// accept determines whether an incoming frame should be accepted.
+// dst is the destination address in the Ethernet header.
+func (tr *transport) accept(from *syscall.SockaddrLinklayer, dst net.HardwareAddr) bool {
+	if tr.multicast {
+		return from.Pkttype == syscall.PACKET_MULTICAST && bytes.Equal(dst, tr.remote)
+	}
+	return from.Pkttype == syscall.PACKET_HOST && from.Halen == 6 && net.HardwareAddr(from.Addr[:6]).String() == tr.remote.String()
+}
+
+func (tr *transport) rxLoop() {
+	buffer := make([]byte, maxFrameSize)
+	for {
+		var n int
+		var from syscall.Sockaddr
+		var recvErr error
+		e := tr.conn.Read(func(fd uintptr) bool {
+			n, from, recvErr = syscall.Recvfrom(int(fd), buffer, 0)
+			return recvErr != syscall.EAGAIN
+		})
+		if e == nil {
+			e = recvErr
+		}
+		if e != nil {
+			if tr.isClosed() {
+				break
+			}
+			tr.p.SetState(l3.TransportDown)
+			time.Sleep(errorBackoff)
+			continue
+		}
+		tr.p.SetState(l3.TransportUp)
+
+		if n < ethHeaderSize {
+			continue
+		}
+		if lladdr, ok := from.(*syscall.SockaddrLinklayer); !ok || !tr.accept(lladdr, buffer[0:6]) {
+			continue
+		}
+		payload := buffer[ethHeaderSize:n]
+		var element tlv.Element
+		rest, e := element.Decode(payload)
+		if e != nil {
+			continue
+		}
+		size := len(payload) - len(rest) // discard padding
+		wire := make([]byte, size)
+		copy(wire, payload)
+		tr.p.Rx <- wire
+	}
+	close(tr.p.Rx)
+	tr.p.SetState(l3.TransportClosed)
+}
+
+func (tr *transport) txLoop() {
+	frame := make([]byte, ethHeaderSize, ethHeaderSize+tr.cfg.MTU)
+	copy(frame, tr.header)
+	for wire := range tr.p.Tx {
+		frame = append(frame[:ethHeaderSize], wire...)
+		var sendErr error
+		e := tr.conn.Write(func(fd uintptr) bool {
+			sendErr = syscall.Sendto(int(fd), frame, 0, &tr.dest)
+			return sendErr != syscall.EAGAIN
+		})
+		if e == nil {
+			e = sendErr
+		}
+
+		if e != nil {
+			tr.p.SetState(l3.TransportDown)
+		} else {
+			tr.p.SetState(l3.TransportUp)
+		}
+	}
+	atomic.StoreInt32(&tr.closed, 1)
+	tr.file.Close()
+}
+
+// packetMreq corresponds to struct packet_mreq in <linux/if_packet.h>.
+type packetMreq struct {
+	Ifindex int32
+	Type    uint16
+	Alen    uint16
+	Address [8]byte
+}
+
+func joinMulticast(fd int, ifindex int, group net.HardwareAddr) error {
+	mreq := packetMreq{
+		Ifindex: int32(ifindex),
+		Type:    syscall.PACKET_MR_MULTICAST,
+		Alen:    uint16(len(group)),
+	}
+	copy(mreq.Address[:], group)
+	_, _, errno := syscall.Syscall6(syscall.SYS_SETSOCKOPT, uintptr(fd), syscall.SOL_PACKET, syscall.PACKET_ADD_MEMBERSHIP,
+		uintptr(unsafe.Pointer(&mreq)), unsafe.Sizeof(mreq), 0)
+	if errno != 0 {
+		return os.NewSyscallError("setsockopt", errno)
+	}
+	return nil
+}
+
+func htons(v uint16) uint16 {
+	return v<<8 | v>>8
+}
+
+const (
+	ethHeaderSize = 14
+	maxFrameSize  = 65535
+	errorBackoff  = 10 * time.Millisecond
+)
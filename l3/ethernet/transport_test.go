@@ -0,0 +1,132 @@
+//go:build linux
+// +build linux
+
+package ethernet_test
+
+import (
+	"math/rand"
+	"os/exec"
+	"runtime"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/eric135/go-ndn"
+	"github.com/eric135/go-ndn/l3"
+	"github.com/eric135/go-ndn/l3/ethernet"
+	"github.com/eric135/go-ndn/ndntestenv"
+)
+
+// setupVeth creates a veth pair in a new network namespace.
+// The calling goroutine remains locked to the OS thread that has entered the network namespace,
+// so that the thread is discarded when the test ends.
+func setupVeth(t *testing.T) {
+	runtime.LockOSThread()
+	if e := syscall.Unshare(syscall.CLONE_NEWNET); e != nil {
+		t.Skipf("cannot create network namespace: %v", e)
+	}
+	for _, args := range [][]string{
+		{"link", "add", "veth0", "type", "veth", "peer", "name", "veth1"},
+		{"link", "set", "veth0", "up"},
+		{"link", "set", "veth1", "up"},
+	} {
+		if output, e := exec.Command("ip", args...).CombinedOutput(); e != nil {
+			t.Skipf("cannot create veth pair: %v %s", e, output)
+		}
+	}
+}
+
+func TestUnicast(t *testing.T) {
+	assert, require := makeAR(t)
+	setupVeth(t)
+
+	trA0, e := ethernet.NewMulticastTransport("veth0", ethernet.Config{})
+	require.NoError(e)
+	trB0, e := ethernet.NewMulticastTransport("veth1", ethernet.Config{})
+	require.NoError(e)
+	macA, macB := trA0.Interface().HardwareAddr, trB0.Interface().HardwareAddr
+	close(trA0.Tx())
+	close(trB0.Tx())
+
+	trA, e := ethernet.NewTransport("veth0", macB.String(), ethernet.Config{})
+	require.NoError(e)
+	trB, e := ethernet.NewTransport("veth1", macA.String(), ethernet.Config{})
+	require.NoError(e)
+	assert.Equal(1500, trA.MTU())
+
+	var c ndntestenv.L3FaceTester
+	c.CheckTransport(t, trA, trB)
+}
+
+func TestMulticast(t *testing.T) {
+	assert, require := makeAR(t)
+	setupVeth(t)
+
+	_, e := ethernet.NewTransport("veth0", ethernet.DefaultMulticastGroup, ethernet.Config{})
+	assert.Error(e)
+
+	trA, e := ethernet.NewMulticastTransport("veth0", ethernet.Config{})
+	require.NoError(e)
+	defer close(trA.Tx())
+	trB, e := ethernet.NewMulticastTransport("veth1", ethernet.Config{})
+	require.NoError(e)
+	defer close(trB.Tx())
+	otherGroup := ethernet.Config{MulticastGroup: "01:00:5e:00:17:bb"}
+	trC, e := ethernet.NewMulticastTransport("veth0", otherGroup)
+	require.NoError(e)
+	defer close(trC.Tx())
+	trD, e := ethernet.NewMulticastTransport("veth1", otherGroup)
+	require.NoError(e)
+	defer close(trD.Tx())
+
+	// short frame is padded to minimum Ethernet frame size, which should be discarded
+	trA.Tx() <- []byte{0x05, 0x02, 0xF0, 0xF1}
+	select {
+	case wire := <-trB.Rx():
+		assert.Equal([]byte{0x05, 0x02, 0xF0, 0xF1}, wire)
+	case <-time.After(time.Second):
+		assert.Fail("packet not received")
+	}
+
+	// frames addressed to a different group are not accepted
+	trC.Tx() <- []byte{0x05, 0x01, 0xC0}
+	select {
+	case wire := <-trD.Rx():
+		assert.Equal([]byte{0x05, 0x01, 0xC0}, wire)
+	case <-time.After(time.Second):
+		assert.Fail("packet not received")
+	}
+	select {
+	case wire := <-trB.Rx():
+		assert.Fail("packet received from other group", "%x", wire)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestFragmentation(t *testing.T) {
+	assert, require := makeAR(t)
+	setupVeth(t)
+
+	trA, e := ethernet.NewMulticastTransport("veth0", ethernet.Config{})
+	require.NoError(e)
+	trB, e := ethernet.NewMulticastTransport("veth1", ethernet.Config{})
+	require.NoError(e)
+	faceA, e := l3.NewFace(trA)
+	require.NoError(e)
+	defer close(faceA.Tx())
+	faceB, e := l3.NewFace(trB)
+	require.NoError(e)
+	defer close(faceB.Tx())
+
+	content := make([]byte, 4000)
+	rand.Read(content)
+	faceA.Tx() <- ndn.MakeData(ndn.ParseName("/A"), content)
+
+	select {
+	case pkt := <-faceB.Rx():
+		require.NotNil(pkt.Data)
+		assert.Equal(content, pkt.Data.Content)
+	case <-time.After(time.Second):
+		assert.Fail("packet not received")
+	}
+}
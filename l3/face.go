@@ -3,7 +3,7 @@
 // The Transport interface defines a lower layer communication channel.
 // It knows NDN-TLV structure, but not NDN packet types.
 // It should be implemented for different communication technologies.
-// NDNgo library offers Transport implementations for Unix, UDP, TCP, and AF_PACKET sockets.
+// NDNgo library offers Transport implementations for Unix, UDP, TCP, and AF_PACKET sockets, and WebSocket connections.
 //
 // The Face type is the service exposed to the network layer.
 // It allows sending and receiving packets on a Transport.
@@ -11,6 +11,7 @@ package l3
 
 import (
 	"io"
+	"sync"
 
 	"github.com/eric135/go-ndn"
 	"github.com/eric135/go-ndn/tlv"
@@ -33,15 +34,61 @@ type Face interface {
 
 	State() TransportState
 	OnStateChange(cb func(st TransportState)) io.Closer
+
+	// Counters returns current counters.
+	Counters() FaceCounters
+}
+
+// FaceCounters contains Face counters.
+type FaceCounters struct {
+	// NRxInterests is the number of incoming Interests.
+	NRxInterests uint64 `json:"nRxInterests"`
+
+	// NRxData is the number of incoming Data.
+	NRxData uint64 `json:"nRxData"`
+
+	// NRxNacks is the number of incoming Nacks.
+	NRxNacks uint64 `json:"nRxNacks"`
+
+	// NRxBytes is the total size of TLV elements received from the transport.
+	NRxBytes uint64 `json:"nRxBytes"`
+
+	// NTxInterests is the number of outgoing Interests.
+	NTxInterests uint64 `json:"nTxInterests"`
+
+	// NTxData is the number of outgoing Data.
+	NTxData uint64 `json:"nTxData"`
+
+	// NTxNacks is the number of outgoing Nacks.
+	NTxNacks uint64 `json:"nTxNacks"`
+
+	// NTxBytes is the total size of TLV elements sent to the transport.
+	NTxBytes uint64 `json:"nTxBytes"`
+
+	// NDecodeErrs is the number of incoming frames dropped due to decoding or reassembly errors.
+	NDecodeErrs uint64 `json:"nDecodeErrs"`
+
+	// NEncodeErrs is the number of outgoing packets dropped due to encoding or fragmentation errors.
+	NEncodeErrs uint64 `json:"nEncodeErrs"`
+
+	// LastError is the most recent decoding or encoding error.
+	LastError error `json:"-"`
 }
 
 // NewFace creates a Face.
 // tr.Rx() and tr.Tx() should not be used after this operation.
+//
+// Incoming NDNLPv2 fragments are reassembled.
+// If tr implements TransportMTU, outgoing packets are fragmented to fit within the MTU.
 func NewFace(tr Transport) (Face, error) {
 	f := &face{
-		faceTr: faceTr{tr},
-		rx:     make(chan *ndn.Packet),
-		tx:     make(chan ndn.L3Packet),
+		faceTr:      faceTr{tr},
+		rx:          make(chan *ndn.Packet),
+		tx:          make(chan ndn.L3Packet),
+		reassembler: ndn.NewReassembler(ndn.ReassemblerConfig{}),
+	}
+	if trMTU, ok := tr.(TransportMTU); ok {
+		f.fragmenter = ndn.NewFragmenter(trMTU.MTU())
 	}
 	go f.rxLoop()
 	go f.txLoop()
@@ -50,8 +97,13 @@ func NewFace(tr Transport) (Face, error) {
 
 type face struct {
 	faceTr
-	rx chan *ndn.Packet
-	tx chan ndn.L3Packet
+	rx          chan *ndn.Packet
+	tx          chan ndn.L3Packet
+	fragmenter  *ndn.Fragmenter
+	reassembler *ndn.Reassembler
+
+	cntMu sync.Mutex
+	cnt   FaceCounters
 }
 
 type faceTr struct {
@@ -70,14 +122,57 @@ func (f *face) Tx() chan<- ndn.L3Packet {
 	return f.tx
 }
 
+func (f *face) Counters() FaceCounters {
+	f.cntMu.Lock()
+	defer f.cntMu.Unlock()
+	return f.cnt
+}
+
+func (f *face) count(fn func(cnt *FaceCounters)) {
+	f.cntMu.Lock()
+	defer f.cntMu.Unlock()
+	fn(&f.cnt)
+}
+
+func (f *face) countError(e error, isDecode bool) {
+	f.count(func(cnt *FaceCounters) {
+		if isDecode {
+			cnt.NDecodeErrs++
+		} else {
+			cnt.NEncodeErrs++
+		}
+		cnt.LastError = e
+	})
+}
+
 func (f *face) rxLoop() {
 	for wire := range f.faceTr.Rx() {
-		var packet ndn.Packet
-		e := tlv.Decode(wire, &packet)
+		f.count(func(cnt *FaceCounters) { cnt.NRxBytes += uint64(len(wire)) })
+		var frame ndn.LpPacket
+		e := tlv.Decode(wire, &frame)
+		if e != nil {
+			f.countError(e, true)
+			continue
+		}
+		packet, e := f.reassembler.Accept(&frame)
 		if e != nil {
+			f.countError(e, true)
+			continue
+		}
+		if packet == nil {
 			continue
 		}
-		f.rx <- &packet
+		f.count(func(cnt *FaceCounters) {
+			switch {
+			case packet.Interest != nil:
+				cnt.NRxInterests++
+			case packet.Data != nil:
+				cnt.NRxData++
+			case packet.Nack != nil:
+				cnt.NRxNacks++
+			}
+		})
+		f.rx <- packet
 	}
 	close(f.rx)
 }
@@ -85,11 +180,53 @@ func (f *face) rxLoop() {
 func (f *face) txLoop() {
 	transportTx := f.faceTr.Tx()
 	for l3packet := range f.tx {
-		wire, e := tlv.Encode(l3packet.ToPacket())
+		packet := l3packet.ToPacket()
+		wires, e := f.encode(packet)
 		if e != nil {
+			f.countError(e, false)
 			continue
 		}
-		transportTx <- wire
+
+		f.count(func(cnt *FaceCounters) {
+			switch {
+			case packet.Interest != nil:
+				cnt.NTxInterests++
+			case packet.Data != nil:
+				cnt.NTxData++
+			case packet.Nack != nil:
+				cnt.NTxNacks++
+			}
+			for _, wire := range wires {
+				cnt.NTxBytes += uint64(len(wire))
+			}
+		})
+		for _, wire := range wires {
+			transportTx <- wire
+		}
 	}
 	close(transportTx)
 }
+
+// encode encodes a packet into TLV elements, fragmenting it if necessary.
+func (f *face) encode(packet *ndn.Packet) (wires [][]byte, e error) {
+	if f.fragmenter == nil {
+		wire, e := tlv.Encode(packet)
+		if e != nil {
+			return nil, e
+		}
+		return [][]byte{wire}, nil
+	}
+
+	frames, e := f.fragmenter.Fragment(packet)
+	if e != nil {
+		return nil, e
+	}
+	for _, frame := range frames {
+		wire, e := tlv.Encode(frame)
+		if e != nil {
+			return nil, e
+		}
+		wires = append(wires, wire)
+	}
+	return wires, nil
+}
@@ -0,0 +1,47 @@
+package l3_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/eric135/go-ndn"
+	"github.com/eric135/go-ndn/l3"
+	"github.com/eric135/go-ndn/tlv"
+)
+
+func TestFaceCounters(t *testing.T) {
+	assert, require := makeAR(t)
+
+	trA, trB := l3.NewMemoryTransportPair(l3.MemoryTransportConfig{MTU: 200})
+	faceA, e := l3.NewFace(trA)
+	require.NoError(e)
+	defer close(faceA.Tx())
+	defer close(trB.Tx())
+
+	faceA.Tx() <- ndn.MakeInterest("/A")
+	faceA.Tx() <- ndn.MakeData("/B", make([]byte, 500))
+	faceA.Tx() <- ndn.MakeNack(ndn.MakeInterest("/C"))
+	var nTxBytes int
+	for _, wire := range recvWires(trB, 50*time.Millisecond) {
+		nTxBytes += len(wire)
+	}
+
+	trB.Tx() <- []byte{0x05, 0x03, 0x07, 0x01, 0x08} // incomplete Interest
+	interest := ndn.MakeInterest("/D")
+	wire, _ := tlv.Encode(interest.ToPacket())
+	trB.Tx() <- wire
+	pkt := <-faceA.Rx()
+	require.NotNil(pkt.Interest)
+
+	cnt := faceA.Counters()
+	assert.EqualValues(1, cnt.NTxInterests)
+	assert.EqualValues(1, cnt.NTxData)
+	assert.EqualValues(1, cnt.NTxNacks)
+	assert.EqualValues(nTxBytes, cnt.NTxBytes)
+	assert.EqualValues(1, cnt.NRxInterests)
+	assert.EqualValues(0, cnt.NRxData)
+	assert.EqualValues(1, cnt.NDecodeErrs)
+	assert.EqualValues(0, cnt.NEncodeErrs)
+	assert.Error(cnt.LastError)
+	assert.EqualValues(5+len(wire), cnt.NRxBytes)
+}
@@ -0,0 +1,137 @@
+package l3
+
+import (
+	"sort"
+
+	"github.com/eric135/go-ndn"
+	"github.com/eric135/go-ndn/tlv"
+)
+
+// FibEntry represents an entry in the forwarding information base.
+type FibEntry struct {
+	Name     ndn.Name
+	Nexthops []Nexthop // sorted by increasing cost
+}
+
+// Fib represents the forwarding information base of a Forwarder.
+// Routes are added and removed via FwFace.AddRoute and FwFace.RemoveRoute.
+type Fib interface {
+	// Entries returns a snapshot of FIB entries, sorted by name.
+	Entries() []FibEntry
+}
+
+// fibNode is a node in the FIB name tree.
+// Each node represents a name prefix; it is a FIB entry if it has nexthops.
+type fibNode struct {
+	parent   *fibNode
+	key      string // TLV encoding of the last name component
+	name     ndn.Name
+	children map[string]*fibNode
+	nexthops map[*fwFace]int // cost
+}
+
+// fib is a name tree where child nodes are keyed by TLV encoding of name components.
+// Longest prefix match takes O(name length) map lookups regardless of the number of routes.
+type fib struct {
+	fw   *forwarder
+	root *fibNode
+}
+
+func newFib(fw *forwarder) *fib {
+	return &fib{
+		fw:   fw,
+		root: newFibNode(nil, "", ndn.Name{}),
+	}
+}
+
+func newFibNode(parent *fibNode, key string, name ndn.Name) *fibNode {
+	return &fibNode{
+		parent:   parent,
+		key:      key,
+		name:     name,
+		children: make(map[string]*fibNode),
+		nexthops: make(map[*fwFace]int),
+	}
+}
+
+func (fib *fib) Entries() (entries []FibEntry) {
+	fib.fw.execute(func() {
+		var walk func(node *fibNode)
+		walk = func(node *fibNode) {
+			if len(node.nexthops) > 0 {
+				entries = append(entries, FibEntry{
+					Name:     node.name,
+					Nexthops: node.listNexthops(),
+				})
+			}
+			for _, child := range node.children {
+				walk(child)
+			}
+		}
+		walk(fib.root)
+	})
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name.Compare(entries[j].Name) < 0 })
+	return entries
+}
+
+// Insert adds or updates a nexthop.
+func (fib *fib) Insert(name ndn.Name, f *fwFace, cost int) {
+	node := fib.root
+	for i, comp := range name {
+		key := fibComponentKey(comp)
+		child := node.children[key]
+		if child == nil {
+			child = newFibNode(node, key, name.GetPrefix(i+1))
+			node.children[key] = child
+		}
+		node = child
+	}
+	node.nexthops[f] = cost
+}
+
+// Remove deletes a nexthop, and prunes empty nodes.
+func (fib *fib) Remove(name ndn.Name, f *fwFace) {
+	node := fib.root
+	for _, comp := range name {
+		if node = node.children[fibComponentKey(comp)]; node == nil {
+			return
+		}
+	}
+	delete(node.nexthops, f)
+	for ; node.parent != nil && len(node.nexthops) == 0 && len(node.children) == 0; node = node.parent {
+		delete(node.parent.children, node.key)
+	}
+}
+
+// Lpm returns nexthops of the longest prefix match FIB entry.
+func (fib *fib) Lpm(name ndn.Name) []Nexthop {
+	match := fib.root
+	node := fib.root
+	for _, comp := range name {
+		if node = node.children[fibComponentKey(comp)]; node == nil {
+			break
+		}
+		if len(node.nexthops) > 0 {
+			match = node
+		}
+	}
+	return match.listNexthops()
+}
+
+func (node *fibNode) listNexthops() (nexthops []Nexthop) {
+	for f, cost := range node.nexthops {
+		nexthops = append(nexthops, Nexthop{f, cost})
+	}
+	sort.Slice(nexthops, func(i, j int) bool {
+		if nexthops[i].Cost != nexthops[j].Cost {
+			return nexthops[i].Cost < nexthops[j].Cost
+		}
+		return nexthops[i].Face.(*fwFace).id < nexthops[j].Face.(*fwFace).id
+	})
+	return nexthops
+}
+
+func fibComponentKey(comp ndn.NameComponent) string {
+	wire, _ := tlv.Encode(comp)
+	return string(wire)
+}
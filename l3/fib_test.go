@@ -0,0 +1,75 @@
+package l3_test
+
+import (
+	"testing"
+
+	"github.com/eric135/go-ndn"
+	"github.com/eric135/go-ndn/l3"
+)
+
+func TestFib(t *testing.T) {
+	assert, require := makeAR(t)
+
+	fw := l3.NewForwarder()
+	_, faceA := addTestFace(require, fw)
+	defer close(faceA.Tx())
+	ff1, face1 := addTestFace(require, fw)
+	defer close(face1.Tx())
+	ff2, face2 := addTestFace(require, fw)
+	ff3, face3 := addTestFace(require, fw)
+	defer close(face3.Tx())
+
+	ff1.AddRoute(ndn.ParseName("/A"), 5)
+	ff2.AddRoute(ndn.ParseName("/A/B"), 8)
+	ff3.AddRoute(ndn.ParseName("/"), 1)
+	ff3.AddRoute(ndn.ParseName("/A/B"), 2)
+
+	entries := fw.Fib().Entries()
+	require.Len(entries, 3)
+	nameEqual(assert, "/", entries[0])
+	nameEqual(assert, "/A", entries[1])
+	nameEqual(assert, "/A/B", entries[2])
+	require.Len(entries[2].Nexthops, 2)
+	assert.Equal(ff3, entries[2].Nexthops[0].Face)
+	assert.Equal(2, entries[2].Nexthops[0].Cost)
+	assert.Equal(ff2, entries[2].Nexthops[1].Face)
+	assert.Equal(8, entries[2].Nexthops[1].Cost)
+
+	checkForward := func(name string, expected ...l3.Face) {
+		faceA.Tx() <- ndn.MakeInterest(name)
+		for _, face := range []l3.Face{face1, face2, face3} {
+			pkt := recvPacket(face)
+			if containsL3Face(expected, face) {
+				if assert.NotNil(pkt, name) {
+					nameEqual(assert, name, pkt.Interest)
+				}
+			} else {
+				assert.Nil(pkt, name)
+			}
+		}
+	}
+	checkForward("/A/B/C", face2, face3)
+	checkForward("/A/X", face1)
+	checkForward("/Z", face3)
+
+	ff3.RemoveRoute(ndn.ParseName("/A/B"))
+	checkForward("/A/B/C", face2)
+
+	close(face2.Tx())
+	ff2.Close()
+	checkForward("/A/B/C", face1)
+
+	entries = fw.Fib().Entries()
+	require.Len(entries, 2)
+	nameEqual(assert, "/", entries[0])
+	nameEqual(assert, "/A", entries[1])
+}
+
+func containsL3Face(faces []l3.Face, face l3.Face) bool {
+	for _, f := range faces {
+		if f == face {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,184 @@
+package l3
+
+import (
+	"time"
+
+	"github.com/eric135/go-ndn"
+	"github.com/eric135/go-ndn/an"
+)
+
+func (fw *forwarder) pitProcess(pkt fwPacket) {
+	switch {
+	case pkt.Interest != nil:
+		fw.pitInterest(pkt)
+	case pkt.Data != nil:
+		fw.pitData(pkt)
+	case pkt.Nack != nil:
+		fw.pitNack(pkt)
+	}
+}
+
+func (fw *forwarder) pitInterest(pkt fwPacket) {
+	if !fw.acceptHopLimit(pkt) {
+		return
+	}
+
+	interest := *pkt.Interest
+	if interest.Nonce.IsZero() {
+		interest.Nonce = ndn.NewNonce()
+	}
+	lifetime := interest.ApplyDefaultLifetime()
+	downstream, token := pkt.face, pkt.Lp.PitToken
+
+	if fw.pit.dnl.Has(interest.Name, interest.Nonce) {
+		fw.sendNack(downstream, interest, token, an.NackDuplicate)
+		return
+	}
+
+	if data := fw.csFind(interest); data != nil {
+		fw.sendData(downstream, data, token)
+		return
+	}
+
+	entry, isNew := fw.pit.Insert(interest)
+	if !isNew && entry.FindNonce(interest.Nonce, downstream) {
+		fw.sendNack(downstream, interest, token, an.NackDuplicate)
+		return
+	}
+
+	now := time.Now()
+	entry.inRecords[downstream] = &pitInRecord{
+		token:     token,
+		nonce:     interest.Nonce,
+		expiry:    now.Add(lifetime),
+		discovery: !pkt.Lp.SelfLearning.NonDiscovery,
+	}
+	fw.pit.Refresh(entry)
+
+	if entry.HasPendingOutRecord(now) { // aggregated
+		return
+	}
+
+	nexthops := fw.pitSelectNexthops(entry, []*fwFace{downstream}, nil)
+	if len(nexthops) == 0 {
+		fw.count(func(cnt *ForwarderCounters) { cnt.NNoRouteDrops++ })
+		delete(entry.inRecords, downstream)
+		if len(entry.inRecords) == 0 {
+			fw.pit.Erase(entry)
+		}
+		fw.sendNack(downstream, interest, token, an.NackNoRoute)
+		return
+	}
+
+	fw.pitForward(entry, interest, pkt.Lp.CongestionMark, nexthops)
+}
+
+// pitForward forwards an Interest to upstream faces, and schedules a retry.
+func (fw *forwarder) pitForward(entry *pitEntry, interest ndn.Interest, congMark uint64, upstreams []*fwFace) {
+	expiry := time.Now().Add(interest.ApplyDefaultLifetime())
+	for _, f := range upstreams {
+		entry.outRecords[f] = &pitOutRecord{
+			nonce:  interest.Nonce,
+			expiry: expiry,
+		}
+		var lph ndn.LpL3
+		lph.PitToken = ndn.PitTokenFromUint(entry.token)
+		lph.CongestionMark = congMark
+		lph.SelfLearning.NonDiscovery = entry.nonDiscovery
+		f.Tx() <- makeInterestPacket(interest, lph, f)
+	}
+
+	if entry.retryTimer != nil {
+		entry.retryTimer.Stop()
+	}
+	entry.retryTimer = time.AfterFunc(fw.cfg.RetryTimeout, func() {
+		fw.cmd <- func() {
+			if fw.pit.byToken[entry.token] == entry {
+				fw.pitRetry(entry)
+			}
+		}
+	})
+}
+
+// pitRetry asks the strategy whether to retry an Interest on other upstream faces.
+// Returns false if the strategy gives up.
+func (fw *forwarder) pitRetry(entry *pitEntry) bool {
+	var downstreams []*fwFace
+	for f := range entry.inRecords {
+		downstreams = append(downstreams, f)
+	}
+	var tried []FwFace
+	for f := range entry.outRecords {
+		tried = append(tried, f)
+	}
+
+	nexthops := fw.pitSelectNexthops(entry, downstreams, tried)
+	if len(nexthops) == 0 {
+		return false
+	}
+	fw.pitForward(entry, entry.interest, 0, nexthops)
+	return true
+}
+
+func (fw *forwarder) pitData(pkt fwPacket) {
+	entry := fw.pit.FindByToken(pkt.Lp.PitToken)
+	if entry == nil {
+		fw.count(func(cnt *ForwarderCounters) { cnt.NUnknownTokenData++ })
+		return
+	}
+	if entry.outRecords[pkt.face] == nil || !pkt.Data.CanSatisfy(entry.interest) {
+		return
+	}
+	fw.pit.Erase(entry)
+	fw.csInsert(pkt.Packet)
+	pa := fw.slProcessData(pkt)
+
+	now := time.Now()
+	for f, ir := range entry.inRecords {
+		if !fw.isActive(f) || ir.expiry.Before(now) {
+			continue
+		}
+		dpkt := &ndn.Packet{Data: pkt.Data}
+		dpkt.Lp.PitToken = ir.token
+		dpkt.Lp.CongestionMark = pkt.Lp.CongestionMark
+		if ir.discovery {
+			dpkt.Lp.SelfLearning.PrefixAnnouncement = pa
+		}
+		f.Tx() <- dpkt
+	}
+}
+
+func (fw *forwarder) pitNack(pkt fwPacket) {
+	entry := fw.pit.FindByToken(pkt.Lp.PitToken)
+	if entry == nil {
+		return
+	}
+	or := entry.outRecords[pkt.face]
+	if or == nil || or.nonce != pkt.Nack.Interest.Nonce {
+		return
+	}
+	or.nackReason = pkt.Nack.Reason
+
+	now := time.Now()
+	if entry.HasPendingOutRecord(now) || fw.pitRetry(entry) {
+		return
+	}
+
+	// all upstreams have Nacked, return the least severe reason to downstreams
+	reason := uint8(an.NackUnspecified)
+	for _, or := range entry.outRecords {
+		if or.nackReason != an.NackNone && or.nackReason < reason {
+			reason = or.nackReason
+		}
+	}
+	fw.pit.Erase(entry)
+
+	for f, ir := range entry.inRecords {
+		if !fw.isActive(f) || ir.expiry.Before(now) {
+			continue
+		}
+		interest := entry.interest
+		interest.Nonce = ir.nonce
+		fw.sendNack(f, interest, ir.token, reason)
+	}
+}
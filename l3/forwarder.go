@@ -3,8 +3,10 @@ package l3
 import (
 	"math/rand"
 	"sync"
+	"time"
 
 	"github.com/eric135/go-ndn"
+	"github.com/eric135/go-ndn/an"
 	"github.com/jwangsadinata/go-multimap"
 	"github.com/jwangsadinata/go-multimap/setmultimap"
 )
@@ -12,13 +14,41 @@ import (
 // Forwarder is a logical forwarding plane.
 // Its main purpose is to demultiplex incoming packets among faces, where a 'face' is defined as a duplex stream of packets.
 //
-// This is a simplified forwarder with several limitations.
-//  - There is no loop prevention: no Nonce list and no decrementing HopLimit.
-//    If multiple uplinks have "/" route, Interests will be forwarded among them and might cause persistent loops.
+// This is a simplified forwarder. By default, it has several limitations.
+//  - There is no Nonce list for loop prevention.
+//    If multiple uplinks have "/" route, Interests will be forwarded among them and might cause loops.
+//    HopLimit is decremented when an Interest is forwarded to a non-local face, and Interests arriving with zero HopLimit are dropped,
+//    but Interests without HopLimit could loop persistently.
 //    Thus, it is not recommended to connect to multiple uplinks.
 //  - There is no pending Interest table. Instead, downstream 'face' ID is inserted as part of the PIT token.
 //    Since the NDN-DPDK forwarder expects 8-octet PIT tokens, this takes away some space.
 //    Thus, consumers are allowed to use a PIT token up to 4 octets; Interests with longer PIT tokens may be dropped.
+//  - Nacks are returned to the downstream face identified by the PIT token, in the same way as Data.
+//    An Interest that matches no route is answered with a NoRoute Nack.
+//
+// If ForwarderConfig.EnablePit is set, the forwarder maintains a pending Interest table instead.
+//  - Interests with the same Name, CanBePrefix, and MustBeFresh are aggregated into one PIT entry.
+//    Each PIT entry records downstream faces with their PIT tokens, and upstream faces with their Nonces.
+//  - Interests are never forwarded back to the downstream face.
+//    A dead Nonce list detects looping Interests, which are answered with a Duplicate Nack.
+//  - Upstream PIT tokens are assigned by the forwarder, so that consumers may use PIT tokens of any length.
+//  - Data is matched to a PIT entry by PIT token, and must satisfy the pending Interest.
+//  - A PIT entry expires after the InterestLifetime of its downstream Interests.
+//  - If the strategy permits, an Interest is retried on other upstream faces after RetryTimeout or when all upstreams Nack.
+//
+// Interests are forwarded according to the Strategy chosen by longest prefix match in the strategy choice table.
+// The default strategy is ForwarderConfig.DefaultStrategy, or MulticastStrategy if unspecified.
+//
+// If ForwarderConfig.ContentStore is set, Interests are answered from the ContentStore when possible,
+// and incoming Data are inserted into the ContentStore unless NDNLPv2 CachePolicy indicates NoCache.
+//
+// SelfLearningStrategy requires ForwarderConfig.EnablePit; without a PIT, it behaves as MulticastStrategy.
+//  - An Interest that matches a route is forwarded to the nexthops with the NDNLPv2 NonDiscovery header.
+//  - A discovery Interest that matches no route is broadcast to non-local multi-access faces, see TransportMultiAccess.
+//  - When Data arrives with an NDNLPv2 PrefixAnnouncement header, a route toward the incoming face is learned.
+//    The route expires according to the ExpirationPeriod and ValidityPeriod of the prefix announcement.
+//  - When a local producer replies to a discovery Interest, the forwarder attaches a prefix announcement of
+//    the longest matching announced prefix of the producer face, see FwFace.AddAnnouncement.
 type Forwarder interface {
 	// AddTransport constructs a Face and invokes AddFace.
 	AddTransport(tr Transport) (FwFace, error)
@@ -41,27 +71,145 @@ type Forwarder interface {
 	//  - Announcements are not withdrawn before removing dest.
 	//  - There is no error handling.
 	RemoveReadvertiseDestination(dest ReadvertiseDestination)
+
+	// Fib returns the forwarding information base.
+	Fib() Fib
+
+	// SetStrategy sets the strategy for Interests under a name prefix.
+	SetStrategy(prefix ndn.Name, strategy Strategy)
+
+	// UnsetStrategy removes the strategy choice of a name prefix.
+	// Unsetting the strategy of "/" reverts it to the default strategy.
+	UnsetStrategy(prefix ndn.Name)
+
+	// Counters returns current counters.
+	Counters() ForwarderCounters
+}
+
+// ForwarderCounters contains Forwarder counters.
+type ForwarderCounters struct {
+	// NNoRouteDrops is the number of incoming Interests dropped because no upstream face is available.
+	NNoRouteDrops uint64 `json:"nNoRouteDrops"`
+
+	// NUnknownTokenData is the number of incoming Data dropped because the PIT token does not identify a downstream.
+	NUnknownTokenData uint64 `json:"nUnknownTokenData"`
+}
+
+// ForwarderConfig contains Forwarder configuration.
+type ForwarderConfig struct {
+	// EnablePit enables the pending Interest table.
+	EnablePit bool
+
+	// DeadNonceLifetime is the duration a Nonce is kept in the dead Nonce list after its PIT entry is erased.
+	// This is effective only if EnablePit is set.
+	// The default is DefaultDeadNonceLifetime.
+	DeadNonceLifetime time.Duration
+
+	// RetryTimeout is the duration after which an unanswered Interest may be retried on other upstream faces.
+	// This is effective only if EnablePit is set.
+	// The default is DefaultRetryTimeout.
+	RetryTimeout time.Duration
+
+	// ContentStore is a cache of Data packets.
+	// If nil, there is no caching.
+	ContentStore ContentStore
+
+	// DefaultStrategy is the strategy for "/" prefix.
+	// The default is MulticastStrategy.
+	DefaultStrategy Strategy
+
+	// SelfLearningSigner signs prefix announcements generated for local producers under SelfLearningStrategy.
+	// The default is ndn.DigestSigning.
+	SelfLearningSigner ndn.Signer
+
+	// SelfLearningExpiration is the ExpirationPeriod of prefix announcements generated for local producers.
+	// The default is DefaultSelfLearningExpiration.
+	SelfLearningExpiration time.Duration
+}
+
+func (cfg *ForwarderConfig) applyDefaults() {
+	if cfg.DeadNonceLifetime <= 0 {
+		cfg.DeadNonceLifetime = DefaultDeadNonceLifetime
+	}
+	if cfg.RetryTimeout <= 0 {
+		cfg.RetryTimeout = DefaultRetryTimeout
+	}
+	if cfg.DefaultStrategy == nil {
+		cfg.DefaultStrategy = MulticastStrategy
+	}
+	if cfg.SelfLearningSigner == nil {
+		cfg.SelfLearningSigner = ndn.DigestSigning
+	}
+	if cfg.SelfLearningExpiration <= 0 {
+		cfg.SelfLearningExpiration = DefaultSelfLearningExpiration
+	}
 }
 
-// NewForwarder creates a Forwarder.
+// ForwarderConfig defaults.
+const (
+	DefaultDeadNonceLifetime      = 6 * time.Second
+	DefaultRetryTimeout           = 500 * time.Millisecond
+	DefaultSelfLearningExpiration = time.Hour
+)
+
+// NewForwarder creates a Forwarder with default configuration.
 func NewForwarder() Forwarder {
+	return NewForwarderWithConfig(ForwarderConfig{})
+}
+
+// NewForwarderWithConfig creates a Forwarder.
+func NewForwarderWithConfig(cfg ForwarderConfig) Forwarder {
+	cfg.applyDefaults()
 	fw := &forwarder{
+		cfg:           cfg,
 		faces:         make(map[uint16]*fwFace),
 		announcements: setmultimap.New(),
 		readvertise:   make(map[ReadvertiseDestination]bool),
+		strategies:    make(map[string]Strategy),
+		paCache:       make(map[string]*ndn.Data),
 		cmd:           make(chan func()),
-		pkt:           make(chan *ndn.Packet),
+		pkt:           make(chan fwPacket),
+	}
+	fw.fib = newFib(fw)
+	if cfg.EnablePit {
+		fw.pit = newPit(cfg.DeadNonceLifetime)
 	}
 	go fw.loop()
 	return fw
 }
 
+// fwPacket is a packet received on a FwFace.
+type fwPacket struct {
+	*ndn.Packet
+	face *fwFace
+}
+
 type forwarder struct {
+	cfg           ForwarderConfig
 	faces         map[uint16]*fwFace
 	announcements multimap.MultiMap // multimap[string(prefixV)]*fwFace
 	readvertise   map[ReadvertiseDestination]bool
+	fib           *fib
+	strategies    map[string]Strategy  // strategy choice table, keyed by string(prefixV)
+	paCache       map[string]*ndn.Data // generated prefix announcements, keyed by string(prefixV)
 	cmd           chan func()
-	pkt           chan *ndn.Packet
+	pkt           chan fwPacket
+	pit           *pit // nil if PIT is disabled
+
+	cntMu sync.Mutex
+	cnt   ForwarderCounters
+}
+
+func (fw *forwarder) Counters() ForwarderCounters {
+	fw.cntMu.Lock()
+	defer fw.cntMu.Unlock()
+	return fw.cnt
+}
+
+func (fw *forwarder) count(fn func(cnt *ForwarderCounters)) {
+	fw.cntMu.Lock()
+	defer fw.cntMu.Unlock()
+	fn(&fw.cnt)
 }
 
 func (fw *forwarder) AddTransport(tr Transport) (FwFace, error) {
@@ -76,8 +224,11 @@ func (fw *forwarder) AddFace(face Face) (ff FwFace, e error) {
 	f := &fwFace{
 		Face:          face,
 		fw:            fw,
+		local:         isLocalFace(face),
+		multiAccess:   isMultiAccessFace(face),
 		routes:        make(map[string]ndn.Name),
 		announcements: make(map[string]ndn.Name),
+		learned:       make(map[string]*learnedRoute),
 	}
 
 	fw.execute(func() {
@@ -125,47 +276,176 @@ func (fw *forwarder) execute(fn func()) {
 }
 
 func (fw *forwarder) loop() {
+	expiryTimer := time.NewTimer(time.Hour)
+	expiryTimer.Stop()
 	for {
 		select {
 		case fn := <-fw.cmd:
 			fn()
 		case pkt := <-fw.pkt:
+			if fw.pit != nil {
+				fw.pitProcess(pkt)
+				break
+			}
 			switch {
 			case pkt.Interest != nil:
 				fw.forwardInterest(pkt)
-			case pkt.Data != nil:
-				fw.forwardData(pkt)
+			case pkt.Data != nil, pkt.Nack != nil:
+				fw.forwardDataNack(pkt.Packet)
+			}
+		case <-expiryTimer.C:
+		}
+
+		if fw.pit != nil {
+			fw.pit.Expire(time.Now())
+			expiryTimer.Stop()
+			if next, ok := fw.pit.NextExpiry(); ok {
+				expiryTimer.Reset(time.Until(next))
 			}
 		}
 	}
 }
 
-func (fw *forwarder) forwardInterest(pkt *ndn.Packet) {
-	lpmLen := -1
-	var nexthops []*fwFace
-	for _, f := range fw.faces {
-		matchLen := f.lpmRoute(pkt.Interest.Name)
-		switch {
-		case matchLen > lpmLen:
-			lpmLen = matchLen
-			nexthops = nil
-			fallthrough
-		case matchLen == lpmLen:
-			nexthops = append(nexthops, f)
+func (fw *forwarder) Fib() Fib {
+	return fw.fib
+}
+
+// lpm returns nexthops of the longest prefix match FIB entry, sorted by increasing cost.
+// Faces in exclude, such as downstream faces, are skipped.
+func (fw *forwarder) lpm(name ndn.Name, exclude ...*fwFace) (nexthops []Nexthop) {
+	for _, nh := range fw.fib.Lpm(name) {
+		if !containsFwFace(exclude, nh.Face.(*fwFace)) {
+			nexthops = append(nexthops, nh)
+		}
+	}
+	return nexthops
+}
+
+// selectNexthops invokes the strategy to choose upstream faces for an Interest.
+func (fw *forwarder) selectNexthops(interest ndn.Interest, candidates []Nexthop, tried []FwFace) (upstreams []*fwFace) {
+	for _, face := range fw.findStrategy(interest.Name).SelectNexthops(interest, candidates, tried) {
+		if f, ok := face.(*fwFace); ok && fw.isActive(f) {
+			upstreams = append(upstreams, f)
 		}
 	}
+	return upstreams
+}
+
+func (fw *forwarder) forwardInterest(pkt fwPacket) {
+	if !fw.acceptHopLimit(pkt) {
+		return
+	}
+
+	if data := fw.csFind(*pkt.Interest); data != nil {
+		id, token := tokenStripID(pkt.Lp.PitToken)
+		fw.sendData(fw.faces[id], data, token)
+		return
+	}
+
+	nexthops := fw.selectNexthops(*pkt.Interest, fw.lpm(pkt.Interest.Name, pkt.face), nil)
+	if len(nexthops) == 0 {
+		fw.count(func(cnt *ForwarderCounters) { cnt.NNoRouteDrops++ })
+		id, token := tokenStripID(pkt.Lp.PitToken)
+		fw.sendNack(fw.faces[id], *pkt.Interest, token, an.NackNoRoute)
+		return
+	}
 
 	for _, f := range nexthops {
-		f.Tx() <- pkt
+		f.Tx() <- makeInterestPacket(*pkt.Interest, pkt.Lp, f)
+	}
+}
+
+// acceptHopLimit drops an incoming Interest with zero HopLimit.
+func (fw *forwarder) acceptHopLimit(pkt fwPacket) bool {
+	if hopLimit := pkt.Interest.HopLimit; hopLimit != nil && *hopLimit == 0 {
+		pkt.face.count(func(cnt *FwFaceCounters) { cnt.NHopLimitDrops++ })
+		return false
+	}
+	return true
+}
+
+// makeInterestPacket creates an outgoing Interest packet, decrementing HopLimit if the upstream face is non-local.
+func makeInterestPacket(interest ndn.Interest, lph ndn.LpL3, upstream *fwFace) *ndn.Packet {
+	if hopLimit := interest.HopLimit; hopLimit != nil && !upstream.local {
+		decremented := *hopLimit - 1
+		interest.HopLimit = &decremented
+	}
+	return &ndn.Packet{Lp: lph, Interest: &interest}
+}
+
+// isLocalFace determines whether a face communicates with local applications.
+// A face that has no transport, such as an endpoint's logical face, may implement TransportLocal itself.
+func isLocalFace(face Face) bool {
+	if faceLocal, ok := face.(TransportLocal); ok {
+		return faceLocal.IsLocal()
+	}
+	trLocal, ok := face.Transport().(TransportLocal)
+	return ok && trLocal.IsLocal()
+}
+
+// isMultiAccessFace determines whether a face reaches multiple peers.
+func isMultiAccessFace(face Face) bool {
+	trMultiAccess, ok := face.Transport().(TransportMultiAccess)
+	return ok && trMultiAccess.IsMultiAccess()
+}
+
+// isActive determines whether a face is still attached to the forwarder.
+func (fw *forwarder) isActive(f *fwFace) bool {
+	return f != nil && fw.faces[f.id] == f
+}
+
+// csFind looks up the ContentStore for Data that can satisfy an Interest.
+func (fw *forwarder) csFind(interest ndn.Interest) *ndn.Data {
+	if fw.cfg.ContentStore == nil {
+		return nil
+	}
+	return fw.cfg.ContentStore.Find(interest)
+}
+
+// csInsert inserts Data into the ContentStore, unless prohibited by CachePolicy.
+func (fw *forwarder) csInsert(pkt *ndn.Packet) {
+	if fw.cfg.ContentStore == nil || pkt.Lp.CachePolicyType == an.CachePolicyNoCache {
+		return
 	}
+	fw.cfg.ContentStore.Insert(*pkt.Data)
 }
 
-func (fw *forwarder) forwardData(pkt *ndn.Packet) {
+// sendData returns Data to a downstream face.
+func (fw *forwarder) sendData(f *fwFace, data *ndn.Data, token []byte) {
+	if !fw.isActive(f) {
+		return
+	}
+	dpkt := &ndn.Packet{Data: data}
+	dpkt.Lp.PitToken = token
+	f.Tx() <- dpkt
+}
+
+// sendNack returns a Nack to a downstream face.
+func (fw *forwarder) sendNack(f *fwFace, interest ndn.Interest, token []byte, reason uint8) {
+	if !fw.isActive(f) {
+		return
+	}
+	nack := ndn.MakeNack(interest, reason)
+	npkt := nack.ToPacket()
+	npkt.Lp.PitToken = token
+	f.Tx() <- npkt
+}
+
+func (fw *forwarder) forwardDataNack(pkt *ndn.Packet) {
 	id, token := tokenStripID(pkt.Lp.PitToken)
-	if f := fw.faces[id]; f != nil {
-		pkt.Lp.PitToken = token
-		f.Tx() <- pkt
+	f := fw.faces[id]
+	if f == nil {
+		if pkt.Data != nil {
+			fw.count(func(cnt *ForwarderCounters) { cnt.NUnknownTokenData++ })
+		}
+		return
 	}
+
+	if pkt.Data != nil {
+		fw.csInsert(pkt)
+	}
+	pkt.Lp.PitToken = token
+	f.Tx() <- pkt
 }
 
 var (
@@ -191,8 +471,8 @@ func DeleteDefaultForwarder() {
 // AddUplink adds a transport to the default Forwarder and sets the route "/" on the face.
 func AddUplink(tr Transport) (f FwFace, e error) {
 	f, e = GetDefaultForwarder().AddTransport(tr)
-	if e != nil {
-		f.AddRoute(ndn.Name{})
+	if e == nil {
+		f.AddRoute(ndn.Name{}, 0)
 	}
 	return f, e
 }
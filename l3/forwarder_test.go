@@ -0,0 +1,200 @@
+package l3_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/eric135/go-ndn"
+	"github.com/eric135/go-ndn/an"
+	"github.com/eric135/go-ndn/l3"
+	"github.com/stretchr/testify/require"
+)
+
+// addTestFace adds a face to the forwarder, and returns the FwFace and the other end of the face.
+func addTestFace(require *require.Assertions, fw l3.Forwarder) (l3.FwFace, l3.Face) {
+	trA, trB := l3.NewMemoryTransportPair(l3.MemoryTransportConfig{})
+	ff, e := fw.AddTransport(trA)
+	require.NoError(e)
+	face, e := l3.NewFace(trB)
+	require.NoError(e)
+	return ff, face
+}
+
+// recvPacket receives a packet from the face, or returns nil upon timeout.
+func recvPacket(face l3.Face) *ndn.Packet {
+	select {
+	case pkt := <-face.Rx():
+		return pkt
+	case <-time.After(200 * time.Millisecond):
+		return nil
+	}
+}
+
+func makeTokenLpL3(token []byte) (lph ndn.LpL3) {
+	lph.PitToken = token
+	return lph
+}
+
+func TestForwarderNoRoute(t *testing.T) {
+	assert, require := makeAR(t)
+
+	fw := l3.NewForwarder()
+	ff, face := addTestFace(require, fw)
+	defer close(face.Tx())
+
+	face.Tx() <- ndn.MakeInterest("/A", makeTokenLpL3(ndn.PitTokenFromUint(0xA0A1A2A3)))
+
+	pkt := recvPacket(face)
+	require.NotNil(pkt)
+	require.NotNil(pkt.Nack)
+	assert.EqualValues(an.NackNoRoute, pkt.Nack.Reason)
+	nameEqual(assert, "/A", pkt.Nack)
+	assert.Equal(uint64(0xA0A1A2A3), ndn.PitTokenToUint(pkt.Lp.PitToken))
+
+	face.Tx() <- ndn.MakeData("/B", makeTokenLpL3([]byte{0xB0}))
+	time.Sleep(10 * time.Millisecond)
+
+	cnt := fw.Counters()
+	assert.EqualValues(1, cnt.NNoRouteDrops)
+	assert.EqualValues(1, cnt.NUnknownTokenData)
+	ffCnt := ff.Counters()
+	assert.EqualValues(1, ffCnt.NRxInterests)
+	assert.EqualValues(1, ffCnt.NRxData)
+	assert.EqualValues(1, ffCnt.NTxNacks)
+	assert.NotZero(ffCnt.NTxBytes)
+}
+
+func TestForwarderPitAggregate(t *testing.T) {
+	assert, require := makeAR(t)
+
+	fw := l3.NewForwarderWithConfig(l3.ForwarderConfig{EnablePit: true})
+	_, faceA := addTestFace(require, fw)
+	defer close(faceA.Tx())
+	_, faceB := addTestFace(require, fw)
+	defer close(faceB.Tx())
+	ffP, faceP := addTestFace(require, fw)
+	defer close(faceP.Tx())
+	ffP.AddRoute(ndn.ParseName("/"), 0)
+
+	tokenA := []byte{0xA0, 0xA1, 0xA2, 0xA3, 0xA4, 0xA5, 0xA6, 0xA7, 0xA8, 0xA9, 0xAA, 0xAB}
+	tokenB := []byte{0xB0}
+	faceA.Tx() <- ndn.MakeInterest("/P", makeTokenLpL3(tokenA))
+	faceB.Tx() <- ndn.MakeInterest("/P", makeTokenLpL3(tokenB))
+
+	pkt := recvPacket(faceP)
+	require.NotNil(pkt)
+	require.NotNil(pkt.Interest)
+	nameEqual(assert, "/P", pkt.Interest)
+	assert.Len(pkt.Lp.PitToken, 8)
+	assert.Nil(recvPacket(faceP)) // second Interest is aggregated
+
+	faceP.Tx() <- ndn.MakeData(pkt.Interest, makeTokenLpL3(pkt.Lp.PitToken))
+
+	pkt = recvPacket(faceA)
+	require.NotNil(pkt)
+	require.NotNil(pkt.Data)
+	nameEqual(assert, "/P", pkt.Data)
+	assert.Equal(tokenA, pkt.Lp.PitToken)
+
+	pkt = recvPacket(faceB)
+	require.NotNil(pkt)
+	require.NotNil(pkt.Data)
+	assert.Equal(tokenB, pkt.Lp.PitToken)
+}
+
+func TestForwarderPitLoop(t *testing.T) {
+	assert, require := makeAR(t)
+
+	fw := l3.NewForwarderWithConfig(l3.ForwarderConfig{EnablePit: true})
+	_, faceA := addTestFace(require, fw)
+	defer close(faceA.Tx())
+	ffU1, faceU1 := addTestFace(require, fw)
+	defer close(faceU1.Tx())
+	ffU2, faceU2 := addTestFace(require, fw)
+	defer close(faceU2.Tx())
+	ffU1.AddRoute(ndn.ParseName("/"), 0)
+	ffU2.AddRoute(ndn.ParseName("/"), 0)
+
+	faceA.Tx() <- ndn.MakeInterest("/L", ndn.NonceFromUint(0xC0C1C2C3), makeTokenLpL3([]byte{0xA0}))
+	pkt1 := recvPacket(faceU1)
+	require.NotNil(pkt1)
+	require.NotNil(pkt1.Interest)
+	pkt2 := recvPacket(faceU2)
+	require.NotNil(pkt2)
+	require.NotNil(pkt2.Interest)
+
+	// U1 forwards the Interest back to the forwarder, which should detect the loop
+	faceU1.Tx() <- ndn.MakeInterest("/L", ndn.NonceFromUint(0xC0C1C2C3), makeTokenLpL3([]byte{0xE1}))
+	pkt := recvPacket(faceU1)
+	require.NotNil(pkt)
+	require.NotNil(pkt.Nack)
+	assert.EqualValues(an.NackDuplicate, pkt.Nack.Reason)
+	assert.Equal([]byte{0xE1}, pkt.Lp.PitToken)
+	assert.Nil(recvPacket(faceU2))
+
+	// both upstreams Nack, downstream receives Nack
+	faceU1.Tx() <- ndn.MakeNack(pkt1.Interest, an.NackCongestion)
+	assert.Nil(recvPacket(faceA))
+	faceU2.Tx() <- ndn.MakeNack(pkt2.Interest, an.NackNoRoute)
+	pkt = recvPacket(faceA)
+	require.NotNil(pkt)
+	require.NotNil(pkt.Nack)
+	assert.EqualValues(an.NackCongestion, pkt.Nack.Reason)
+	assert.Equal([]byte{0xA0}, pkt.Lp.PitToken)
+
+	// PIT entry is erased, and the Nonce is in the dead Nonce list
+	faceU2.Tx() <- ndn.MakeInterest("/L", ndn.NonceFromUint(0xC0C1C2C3), makeTokenLpL3([]byte{0xE2}))
+	pkt = recvPacket(faceU2)
+	require.NotNil(pkt)
+	require.NotNil(pkt.Nack)
+	assert.EqualValues(an.NackDuplicate, pkt.Nack.Reason)
+}
+
+func TestForwarderPitExpire(t *testing.T) {
+	assert, require := makeAR(t)
+
+	fw := l3.NewForwarderWithConfig(l3.ForwarderConfig{EnablePit: true})
+	_, faceA := addTestFace(require, fw)
+	defer close(faceA.Tx())
+	ffP, faceP := addTestFace(require, fw)
+	defer close(faceP.Tx())
+	ffP.AddRoute(ndn.ParseName("/"), 0)
+
+	faceA.Tx() <- ndn.MakeInterest("/E", 100*time.Millisecond, makeTokenLpL3([]byte{0xA0}))
+	pkt := recvPacket(faceP)
+	require.NotNil(pkt)
+	require.NotNil(pkt.Interest)
+
+	time.Sleep(200 * time.Millisecond)
+	faceP.Tx() <- ndn.MakeData(pkt.Interest, makeTokenLpL3(pkt.Lp.PitToken))
+	assert.Nil(recvPacket(faceA))
+}
+
+func TestForwarderHopLimit(t *testing.T) {
+	assert, require := makeAR(t)
+
+	fw := l3.NewForwarder()
+	ffA, faceA := addTestFace(require, fw)
+	defer close(faceA.Tx())
+	ffP, faceP := addTestFace(require, fw)
+	defer close(faceP.Tx())
+	ffP.AddRoute(ndn.ParseName("/"), 0)
+
+	faceA.Tx() <- ndn.MakeInterest("/H/5", ndn.HopLimit(5))
+	pkt := recvPacket(faceP)
+	require.NotNil(pkt)
+	require.NotNil(pkt.Interest)
+	require.NotNil(pkt.Interest.HopLimit)
+	assert.EqualValues(4, *pkt.Interest.HopLimit)
+
+	faceA.Tx() <- ndn.MakeInterest("/H/none")
+	pkt = recvPacket(faceP)
+	require.NotNil(pkt)
+	require.NotNil(pkt.Interest)
+	assert.Nil(pkt.Interest.HopLimit)
+
+	faceA.Tx() <- ndn.MakeInterest("/H/0", ndn.HopLimit(0))
+	assert.Nil(recvPacket(faceP))
+	assert.EqualValues(1, ffA.Counters().NHopLimitDrops)
+	assert.EqualValues(0, ffP.Counters().NHopLimitDrops)
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/binary"
 	"errors"
 	"io"
+	"sync"
 
 	"github.com/eric135/go-ndn"
 )
@@ -23,11 +24,23 @@ type FwFace interface {
 	State() TransportState
 	OnStateChange(cb func(st TransportState)) io.Closer
 
-	AddRoute(name ndn.Name)
+	// AddRoute adds or updates a route with a cost.
+	AddRoute(name ndn.Name, cost int)
 	RemoveRoute(name ndn.Name)
 
 	AddAnnouncement(name ndn.Name)
 	RemoveAnnouncement(name ndn.Name)
+
+	// Counters returns current counters.
+	Counters() FwFaceCounters
+}
+
+// FwFaceCounters contains FwFace counters.
+type FwFaceCounters struct {
+	FaceCounters
+
+	// NHopLimitDrops is the number of incoming Interests dropped due to zero HopLimit.
+	NHopLimitDrops uint64 `json:"nHopLimitDrops"`
 }
 
 func tokenInsertID(oldToken []byte, id uint16) (token []byte) {
@@ -54,27 +67,50 @@ type fwFace struct {
 	Face
 	fw            *forwarder
 	id            uint16
+	local         bool
+	multiAccess   bool
 	routes        map[string]ndn.Name
 	announcements map[string]ndn.Name
+	learned       map[string]*learnedRoute
+
+	cntMu sync.Mutex
+	cnt   FwFaceCounters
+}
+
+func (f *fwFace) Counters() FwFaceCounters {
+	f.cntMu.Lock()
+	defer f.cntMu.Unlock()
+	cnt := f.cnt
+	cnt.FaceCounters = f.Face.Counters()
+	return cnt
+}
+
+func (f *fwFace) count(fn func(cnt *FwFaceCounters)) {
+	f.cntMu.Lock()
+	defer f.cntMu.Unlock()
+	fn(&f.cnt)
 }
 
 func (f *fwFace) rxLoop() {
 	for pkt := range f.Rx() {
 		switch {
 		case pkt.Interest != nil:
-			pkt.Lp.PitToken = tokenInsertID(pkt.Lp.PitToken, f.id)
-			f.fw.pkt <- pkt
-		case pkt.Data != nil:
-			f.fw.pkt <- pkt
+			if f.fw.pit == nil {
+				pkt.Lp.PitToken = tokenInsertID(pkt.Lp.PitToken, f.id)
+			}
+			f.fw.pkt <- fwPacket{pkt, f}
+		case pkt.Data != nil, pkt.Nack != nil:
+			f.fw.pkt <- fwPacket{pkt, f}
 		}
 	}
 }
 
-func (f *fwFace) AddRoute(name ndn.Name) {
+func (f *fwFace) AddRoute(name ndn.Name, cost int) {
 	nameV, _ := name.MarshalBinary()
 	nameS := string(nameV)
 	f.fw.execute(func() {
 		f.routes[nameS] = name
+		f.fw.fib.Insert(name, f, cost)
 	})
 }
 
@@ -83,16 +119,12 @@ func (f *fwFace) RemoveRoute(name ndn.Name) {
 	nameS := string(nameV)
 	f.fw.execute(func() {
 		delete(f.routes, nameS)
-	})
-}
-
-func (f *fwFace) lpmRoute(name ndn.Name) int {
-	for _, name := range f.routes {
-		if name.IsPrefixOf(name) {
-			return len(name)
+		if f.learned[nameS] != nil && f.fw.isActive(f) { // keep the learned route
+			f.fw.fib.Insert(name, f, 0)
+		} else {
+			f.fw.fib.Remove(name, f)
 		}
-	}
-	return -1
+	})
 }
 
 func (f *fwFace) AddAnnouncement(name ndn.Name) {
@@ -123,6 +155,7 @@ func (f *fwFace) removeAnnouncementImpl(name ndn.Name, nameS string) {
 
 	f.fw.announcements.Remove(nameS, f)
 	if !f.fw.announcements.ContainsKey(nameS) {
+		delete(f.fw.paCache, nameS)
 		for dest := range f.fw.readvertise {
 			dest.Withdraw(name)
 		}
@@ -134,6 +167,13 @@ func (f *fwFace) Close() error {
 		for nameS, name := range f.announcements {
 			f.removeAnnouncementImpl(name, nameS)
 		}
+		for _, name := range f.routes {
+			f.fw.fib.Remove(name, f)
+		}
+		for _, lr := range f.learned {
+			lr.timer.Stop()
+			f.fw.fib.Remove(lr.name, f)
+		}
 		delete(f.fw.faces, f.id)
 		close(f.Tx())
 	})
@@ -0,0 +1,385 @@
+package l3
+
+import (
+	"container/heap"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// MemoryTransportConfig contains in-memory transport configuration.
+// Link properties apply to each direction independently.
+type MemoryTransportConfig struct {
+	TransportQueueConfig
+
+	// Latency is the one-way delay of each packet.
+	// The default is zero.
+	Latency time.Duration
+
+	// LossRate is the probability that a packet is lost, between 0.0 and 1.0.
+	// The default is zero.
+	LossRate float64
+
+	// ReorderRate is the probability that a packet is delayed by an additional ReorderDelay,
+	// so that it may arrive after subsequent packets.
+	// The default is zero.
+	ReorderRate float64
+
+	// ReorderDelay is the additional delay of a reordered packet.
+	// The default is DefaultMemoryReorderDelay.
+	ReorderDelay time.Duration
+
+	// Bandwidth is the link capacity in bytes per second.
+	// Packets are queued and serialized at this rate.
+	// The default is zero, which means unlimited.
+	Bandwidth int
+
+	// QueueCapacity is the maximum number of bytes queued for serialization when Bandwidth is set.
+	// A packet that would exceed this limit is dropped.
+	// The default is DefaultMemoryQueueCapacity.
+	QueueCapacity int
+
+	// MTU is the maximum size of an outgoing TLV element.
+	// If positive, the transports implement TransportMTU, and larger packets are dropped.
+	// The default is zero, which means unlimited.
+	MTU int
+
+	// Seed initializes the random number generator for loss and reordering decisions.
+	// The same seed yields the same decisions for the same sequence of packets.
+	Seed int64
+
+	// MultiAccess makes the transports implement TransportMultiAccess and report as multi-access,
+	// which simulates a broadcast link with a single peer.
+	// The default is false.
+	MultiAccess bool
+}
+
+func (cfg *MemoryTransportConfig) applyDefaults() {
+	cfg.ApplyTransportQueueConfigDefaults()
+	if cfg.ReorderDelay <= 0 {
+		cfg.ReorderDelay = DefaultMemoryReorderDelay
+	}
+	if cfg.QueueCapacity <= 0 {
+		cfg.QueueCapacity = DefaultMemoryQueueCapacity
+	}
+}
+
+// MemoryTransportConfig defaults.
+const (
+	DefaultMemoryReorderDelay  = 10 * time.Millisecond
+	DefaultMemoryQueueCapacity = 65536
+)
+
+// MemoryTransportCounters contains in-memory transport counters.
+// They refer to packets sent by this transport.
+type MemoryTransportCounters struct {
+	// NTxPackets is the number of packets accepted onto the link.
+	NTxPackets uint64 `json:"nTxPackets"`
+
+	// NLost is the number of packets dropped due to LossRate.
+	NLost uint64 `json:"nLost"`
+
+	// NReordered is the number of packets delayed due to ReorderRate.
+	NReordered uint64 `json:"nReordered"`
+
+	// NMtuDrops is the number of packets dropped for exceeding MTU.
+	NMtuDrops uint64 `json:"nMtuDrops"`
+
+	// NQueueDrops is the number of packets dropped due to QueueCapacity.
+	NQueueDrops uint64 `json:"nQueueDrops"`
+
+	// NDownDrops is the number of packets dropped while the link is down.
+	NDownDrops uint64 `json:"nDownDrops"`
+}
+
+// MemoryTransport is a Transport connected to a peer in the same process.
+// It simulates a network link, and therefore is not considered a local transport.
+type MemoryTransport interface {
+	Transport
+
+	// SetDown brings the link down or up.
+	// This affects both this transport and its peer.
+	// While the link is down, both transports are in TransportDown state, and packets sent in either direction are dropped.
+	SetDown(down bool)
+
+	// Counters returns current counters.
+	Counters() MemoryTransportCounters
+}
+
+// NewMemoryTransportPair creates a pair of connected in-memory transports.
+//
+// Packets sent on one transport are received on the other, subject to simulated link properties in cfg.
+// When one transport is closed, its peer enters TransportDown state.
+// Each transport closes itself, and enters TransportClosed state, only after its TX channel has been closed.
+func NewMemoryTransportPair(cfg MemoryTransportConfig) (trA, trB MemoryTransport) {
+	cfg.applyDefaults()
+	pair := &memoryPair{}
+	a, b := newMemoryTransport(pair, cfg), newMemoryTransport(pair, cfg)
+	a.peer, b.peer = b, a
+	a.out = newMemoryLink(cfg, cfg.Seed, b)
+	b.out = newMemoryLink(cfg, cfg.Seed+1, a)
+	a.in, b.in = b.out, a.out
+
+	for _, tr := range []*memoryTransport{a, b} {
+		go tr.out.run()
+		go tr.txLoop()
+	}
+
+	if cfg.MTU > 0 {
+		return &memoryTransportMTU{a}, &memoryTransportMTU{b}
+	}
+	return a, b
+}
+
+type memoryPair struct {
+	mutex  sync.Mutex
+	down   bool
+	closed int
+}
+
+type memoryTransport struct {
+	*TransportBase
+	p       *TransportBasePriv
+	cfg     MemoryTransportConfig
+	pair    *memoryPair
+	peer    *memoryTransport
+	out     *memoryLink
+	in      *memoryLink
+	closing chan struct{}
+
+	cntMu sync.Mutex
+	cnt   MemoryTransportCounters
+}
+
+func newMemoryTransport(pair *memoryPair, cfg MemoryTransportConfig) *memoryTransport {
+	tr := &memoryTransport{
+		cfg:     cfg,
+		pair:    pair,
+		closing: make(chan struct{}),
+	}
+	tr.TransportBase, tr.p = NewTransportBase(cfg.TransportQueueConfig)
+	return tr
+}
+
+func (tr *memoryTransport) SetDown(down bool) {
+	tr.pair.mutex.Lock()
+	tr.pair.down = down
+	tr.pair.mutex.Unlock()
+	tr.updateState()
+	tr.peer.updateState()
+}
+
+// IsMultiAccess implements TransportMultiAccess.
+func (tr *memoryTransport) IsMultiAccess() bool {
+	return tr.cfg.MultiAccess
+}
+
+func (tr *memoryTransport) Counters() MemoryTransportCounters {
+	tr.cntMu.Lock()
+	defer tr.cntMu.Unlock()
+	return tr.cnt
+}
+
+func (tr *memoryTransport) count(fn func(cnt *MemoryTransportCounters)) {
+	tr.cntMu.Lock()
+	defer tr.cntMu.Unlock()
+	fn(&tr.cnt)
+}
+
+func (tr *memoryTransport) isDown() bool {
+	tr.pair.mutex.Lock()
+	defer tr.pair.mutex.Unlock()
+	return tr.pair.down || tr.pair.closed > 0
+}
+
+func (tr *memoryTransport) updateState() {
+	if tr.isDown() {
+		tr.p.SetState(TransportDown)
+	} else {
+		tr.p.SetState(TransportUp)
+	}
+}
+
+func (tr *memoryTransport) txLoop() {
+	for wire := range tr.p.Tx {
+		frame, ok := tr.admit(wire)
+		if !ok {
+			continue
+		}
+		select {
+		case tr.out.input <- frame:
+		case <-tr.out.done:
+		}
+	}
+	close(tr.out.input)
+
+	tr.pair.mutex.Lock()
+	tr.pair.closed++
+	tr.pair.mutex.Unlock()
+	tr.peer.updateState()
+
+	close(tr.closing)
+	<-tr.in.done
+	close(tr.p.Rx)
+	tr.p.SetState(TransportClosed)
+}
+
+// admit decides whether and when a packet should be delivered to the peer.
+func (tr *memoryTransport) admit(wire []byte) (frame memoryFrame, ok bool) {
+	switch {
+	case tr.isDown():
+		tr.count(func(cnt *MemoryTransportCounters) { cnt.NDownDrops++ })
+		return frame, false
+	case tr.cfg.MTU > 0 && len(wire) > tr.cfg.MTU:
+		tr.count(func(cnt *MemoryTransportCounters) { cnt.NMtuDrops++ })
+		return frame, false
+	}
+	return tr.out.schedule(wire, tr.count)
+}
+
+type memoryTransportMTU struct {
+	*memoryTransport
+}
+
+// MTU implements TransportMTU.
+func (tr *memoryTransportMTU) MTU() int {
+	return tr.cfg.MTU
+}
+
+// memoryLink delivers packets in one direction.
+type memoryLink struct {
+	cfg      MemoryTransportConfig
+	rng      *rand.Rand
+	dst      *memoryTransport
+	input    chan memoryFrame
+	done     chan struct{}
+	nextFree time.Time
+	nextSeq  uint64
+}
+
+func newMemoryLink(cfg MemoryTransportConfig, seed int64, dst *memoryTransport) *memoryLink {
+	return &memoryLink{
+		cfg:   cfg,
+		rng:   rand.New(rand.NewSource(seed)),
+		dst:   dst,
+		input: make(chan memoryFrame, cfg.TxQueueSize),
+		done:  make(chan struct{}),
+	}
+}
+
+// schedule applies loss, bandwidth, queue capacity, latency, and reordering to a packet.
+// This is called from the sender's txLoop only.
+func (l *memoryLink) schedule(wire []byte, count func(fn func(cnt *MemoryTransportCounters))) (frame memoryFrame, ok bool) {
+	if l.cfg.LossRate > 0 && l.rng.Float64() < l.cfg.LossRate {
+		count(func(cnt *MemoryTransportCounters) { cnt.NLost++ })
+		return frame, false
+	}
+
+	now := time.Now()
+	frame.due = now
+	if l.cfg.Bandwidth > 0 {
+		if l.nextFree.Before(now) {
+			l.nextFree = now
+		}
+		backlog := int(l.nextFree.Sub(now) * time.Duration(l.cfg.Bandwidth) / time.Second)
+		if backlog+len(wire) > l.cfg.QueueCapacity { // tail drop
+			count(func(cnt *MemoryTransportCounters) { cnt.NQueueDrops++ })
+			return frame, false
+		}
+		l.nextFree = l.nextFree.Add(time.Duration(len(wire)) * time.Second / time.Duration(l.cfg.Bandwidth))
+		frame.due = l.nextFree
+	}
+	frame.due = frame.due.Add(l.cfg.Latency)
+
+	reordered := l.cfg.ReorderRate > 0 && l.rng.Float64() < l.cfg.ReorderRate
+	if reordered {
+		frame.due = frame.due.Add(l.cfg.ReorderDelay)
+	}
+
+	frame.wire = wire
+	frame.seq = l.nextSeq
+	l.nextSeq++
+	count(func(cnt *MemoryTransportCounters) {
+		cnt.NTxPackets++
+		if reordered {
+			cnt.NReordered++
+		}
+	})
+	return frame, true
+}
+
+func (l *memoryLink) run() {
+	defer close(l.done)
+	var q memoryFrameQueue
+	input := l.input
+	for input != nil || len(q) > 0 {
+		for now := time.Now(); len(q) > 0 && !q[0].due.After(now); {
+			frame := heap.Pop(&q).(memoryFrame)
+			select {
+			case l.dst.p.Rx <- frame.wire:
+			case <-l.dst.closing:
+				return
+			}
+		}
+		if input == nil && len(q) == 0 {
+			break
+		}
+
+		var timer *time.Timer
+		var timerC <-chan time.Time
+		if len(q) > 0 {
+			timer = time.NewTimer(time.Until(q[0].due))
+			timerC = timer.C
+		}
+		select {
+		case frame, ok := <-input:
+			if ok {
+				heap.Push(&q, frame)
+			} else {
+				input = nil
+			}
+		case <-timerC:
+		case <-l.dst.closing:
+			return
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+type memoryFrame struct {
+	wire []byte
+	due  time.Time
+	seq  uint64
+}
+
+// memoryFrameQueue is a priority queue of frames ordered by due time.
+type memoryFrameQueue []memoryFrame
+
+func (q memoryFrameQueue) Len() int {
+	return len(q)
+}
+
+func (q memoryFrameQueue) Less(i, j int) bool {
+	if q[i].due.Equal(q[j].due) {
+		return q[i].seq < q[j].seq
+	}
+	return q[i].due.Before(q[j].due)
+}
+
+func (q memoryFrameQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+}
+
+func (q *memoryFrameQueue) Push(x interface{}) {
+	*q = append(*q, x.(memoryFrame))
+}
+
+func (q *memoryFrameQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	frame := old[n-1]
+	*q = old[:n-1]
+	return frame
+}
@@ -0,0 +1,174 @@
+package l3_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/eric135/go-ndn/l3"
+	"github.com/eric135/go-ndn/ndntestenv"
+)
+
+// recvWires receives TLV elements from a transport until timeout.
+func recvWires(tr l3.Transport, timeout time.Duration) (wires [][]byte) {
+	deadline := time.After(timeout)
+	for {
+		select {
+		case wire := <-tr.Rx():
+			wires = append(wires, wire)
+		case <-deadline:
+			return wires
+		}
+	}
+}
+
+func TestMemoryTransport(t *testing.T) {
+	trA, trB := l3.NewMemoryTransportPair(l3.MemoryTransportConfig{})
+
+	var c ndntestenv.L3FaceTester
+	c.CheckTransport(t, trA, trB)
+}
+
+func TestMemoryTransportLatency(t *testing.T) {
+	assert, _ := makeAR(t)
+	trA, trB := l3.NewMemoryTransportPair(l3.MemoryTransportConfig{Latency: 50 * time.Millisecond})
+	defer close(trA.Tx())
+	defer close(trB.Tx())
+
+	t0 := time.Now()
+	trA.Tx() <- []byte{0x05, 0x00}
+	select {
+	case <-trB.Rx():
+		assert.GreaterOrEqual(int64(time.Since(t0)), int64(50*time.Millisecond))
+	case <-time.After(time.Second):
+		assert.Fail("packet not received")
+	}
+}
+
+func TestMemoryTransportLoss(t *testing.T) {
+	assert, _ := makeAR(t)
+
+	run := func() (nReceived int, cnt l3.MemoryTransportCounters) {
+		trA, trB := l3.NewMemoryTransportPair(l3.MemoryTransportConfig{LossRate: 0.3, Seed: 7})
+		defer close(trB.Tx())
+		go func() {
+			for i := 0; i < 1000; i++ {
+				trA.Tx() <- []byte{0x05, 0x01, byte(i)}
+			}
+			close(trA.Tx())
+		}()
+		return len(recvWires(trB, 200*time.Millisecond)), trA.Counters()
+	}
+
+	nReceived, cnt := run()
+	assert.InDelta(700, nReceived, 60)
+	assert.EqualValues(nReceived, cnt.NTxPackets)
+	assert.EqualValues(1000-nReceived, cnt.NLost)
+
+	nReceived2, _ := run()
+	assert.Equal(nReceived, nReceived2) // deterministic with same seed
+}
+
+func TestMemoryTransportReorder(t *testing.T) {
+	assert, _ := makeAR(t)
+	trA, trB := l3.NewMemoryTransportPair(l3.MemoryTransportConfig{
+		ReorderRate:  0.5,
+		ReorderDelay: 20 * time.Millisecond,
+		Seed:         3,
+	})
+	defer close(trA.Tx())
+	defer close(trB.Tx())
+
+	for i := 0; i < 20; i++ {
+		trA.Tx() <- []byte{0x05, 0x01, byte(i)}
+	}
+	wires := recvWires(trB, 100*time.Millisecond)
+	assert.Len(wires, 20)
+	assert.NotZero(trA.Counters().NReordered)
+
+	inOrder := true
+	for i, wire := range wires {
+		if wire[2] != byte(i) {
+			inOrder = false
+		}
+	}
+	assert.False(inOrder)
+}
+
+func TestMemoryTransportBandwidth(t *testing.T) {
+	assert, _ := makeAR(t)
+	trA, trB := l3.NewMemoryTransportPair(l3.MemoryTransportConfig{Bandwidth: 10000})
+	defer close(trA.Tx())
+	defer close(trB.Tx())
+
+	t0 := time.Now()
+	for i := 0; i < 5; i++ {
+		trA.Tx() <- append([]byte{0x05, 0x81, 0xFE}, make([]byte, 254)...) // 257 octets
+	}
+	wires := recvWires(trB, 50*time.Millisecond)
+	assert.Less(len(wires), 5)
+	wires = append(wires, recvWires(trB, 150*time.Millisecond)...)
+	assert.Len(wires, 5)
+	assert.GreaterOrEqual(int64(time.Since(t0)), int64(5*257*time.Second/10000))
+}
+
+func TestMemoryTransportQueueCapacity(t *testing.T) {
+	assert, _ := makeAR(t)
+	trA, trB := l3.NewMemoryTransportPair(l3.MemoryTransportConfig{Bandwidth: 10000, QueueCapacity: 1000})
+	defer close(trA.Tx())
+	defer close(trB.Tx())
+
+	for i := 0; i < 20; i++ {
+		trA.Tx() <- append([]byte{0x05, 0x81, 0xFE}, make([]byte, 254)...) // 257 octets
+	}
+	wires := recvWires(trB, 500*time.Millisecond)
+	cnt := trA.Counters()
+	assert.GreaterOrEqual(cnt.NQueueDrops, uint64(10))
+	assert.EqualValues(20, cnt.NTxPackets+cnt.NQueueDrops)
+	assert.EqualValues(cnt.NTxPackets, len(wires))
+}
+
+func TestMemoryTransportMTU(t *testing.T) {
+	assert, _ := makeAR(t)
+	trA, trB := l3.NewMemoryTransportPair(l3.MemoryTransportConfig{MTU: 100})
+	defer close(trA.Tx())
+	defer close(trB.Tx())
+	if assert.Implements((*l3.TransportMTU)(nil), trA) {
+		assert.Equal(100, trA.(l3.TransportMTU).MTU())
+	}
+
+	trA.Tx() <- append([]byte{0x05, 0x62}, make([]byte, 98)...)
+	trA.Tx() <- append([]byte{0x05, 0x63}, make([]byte, 99)...)
+	wires := recvWires(trB, 50*time.Millisecond)
+	if assert.Len(wires, 1) {
+		assert.Len(wires[0], 100)
+	}
+	assert.EqualValues(1, trA.Counters().NMtuDrops)
+}
+
+func TestMemoryTransportDown(t *testing.T) {
+	assert, _ := makeAR(t)
+	trA, trB := l3.NewMemoryTransportPair(l3.MemoryTransportConfig{})
+	defer close(trB.Tx())
+
+	trA.SetDown(true)
+	assert.Equal(l3.TransportDown, trA.State())
+	assert.Equal(l3.TransportDown, trB.State())
+	trA.Tx() <- []byte{0x05, 0x00}
+	trB.Tx() <- []byte{0x05, 0x00}
+	assert.Len(recvWires(trB, 20*time.Millisecond), 0)
+	assert.Len(recvWires(trA, 20*time.Millisecond), 0)
+	assert.EqualValues(1, trA.Counters().NDownDrops)
+
+	trB.SetDown(false)
+	assert.Equal(l3.TransportUp, trA.State())
+	assert.Equal(l3.TransportUp, trB.State())
+	trA.Tx() <- []byte{0x05, 0x00}
+	assert.Len(recvWires(trB, 20*time.Millisecond), 1)
+
+	close(trA.Tx())
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(l3.TransportClosed, trA.State())
+	assert.Equal(l3.TransportDown, trB.State())
+	_, ok := <-trA.Rx()
+	assert.False(ok)
+}
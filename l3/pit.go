@@ -0,0 +1,261 @@
+package l3
+
+import (
+	"container/heap"
+	"container/list"
+	"math/rand"
+	"time"
+
+	"github.com/eric135/go-ndn"
+	"github.com/eric135/go-ndn/an"
+)
+
+// pitInRecord records an Interest received from a downstream face.
+type pitInRecord struct {
+	token     []byte
+	nonce     ndn.Nonce
+	expiry    time.Time
+	discovery bool // Interest lacks NonDiscovery header
+}
+
+// pitOutRecord records an Interest forwarded to an upstream face.
+type pitOutRecord struct {
+	nonce      ndn.Nonce
+	expiry     time.Time
+	nackReason uint8
+}
+
+// pitEntry represents a PIT entry.
+type pitEntry struct {
+	key        string
+	token      uint64
+	interest   ndn.Interest
+	inRecords  map[*fwFace]*pitInRecord
+	outRecords map[*fwFace]*pitOutRecord
+	expiry     time.Time
+	heapIndex  int
+	retryTimer *time.Timer
+
+	nonDiscovery bool // forward with NonDiscovery header, see SelfLearningStrategy
+}
+
+// FindNonce determines whether nonce has been seen on a face other than downstream.
+func (entry *pitEntry) FindNonce(nonce ndn.Nonce, downstream *fwFace) bool {
+	for f, ir := range entry.inRecords {
+		if f != downstream && ir.nonce == nonce {
+			return true
+		}
+	}
+	for _, or := range entry.outRecords {
+		if or.nonce == nonce {
+			return true
+		}
+	}
+	return false
+}
+
+// HasDiscoveryInRecord determines whether any downstream has sent a discovery Interest.
+func (entry *pitEntry) HasDiscoveryInRecord() bool {
+	for _, ir := range entry.inRecords {
+		if ir.discovery {
+			return true
+		}
+	}
+	return false
+}
+
+// HasPendingOutRecord determines whether there is an unexpired out-record that has not been Nacked.
+func (entry *pitEntry) HasPendingOutRecord(now time.Time) bool {
+	for _, or := range entry.outRecords {
+		if or.nackReason == an.NackNone && or.expiry.After(now) {
+			return true
+		}
+	}
+	return false
+}
+
+// pit is the pending Interest table.
+type pit struct {
+	byKey     map[string]*pitEntry
+	byToken   map[uint64]*pitEntry
+	queue     pitExpiryQueue
+	nextToken uint64
+	dnl       *deadNonceList
+}
+
+func newPit(deadNonceLifetime time.Duration) *pit {
+	return &pit{
+		byKey:     make(map[string]*pitEntry),
+		byToken:   make(map[uint64]*pitEntry),
+		nextToken: rand.Uint64(),
+		dnl:       newDeadNonceList(deadNonceLifetime),
+	}
+}
+
+// Insert finds or creates a PIT entry for an Interest.
+func (p *pit) Insert(interest ndn.Interest) (entry *pitEntry, isNew bool) {
+	key := makePitKey(interest)
+	if entry = p.byKey[key]; entry != nil {
+		return entry, false
+	}
+
+	entry = &pitEntry{
+		key:        key,
+		interest:   interest,
+		inRecords:  make(map[*fwFace]*pitInRecord),
+		outRecords: make(map[*fwFace]*pitOutRecord),
+	}
+	for entry.token == 0 || p.byToken[entry.token] != nil {
+		entry.token = p.nextToken
+		p.nextToken++
+	}
+	p.byKey[key] = entry
+	p.byToken[entry.token] = entry
+	heap.Push(&p.queue, entry)
+	return entry, true
+}
+
+// FindByToken finds a PIT entry by forwarder-assigned PIT token.
+func (p *pit) FindByToken(token []byte) *pitEntry {
+	if len(token) != 8 {
+		return nil
+	}
+	return p.byToken[ndn.PitTokenToUint(token)]
+}
+
+// Refresh updates the expiration time of a PIT entry according to its in-records.
+func (p *pit) Refresh(entry *pitEntry) {
+	entry.expiry = time.Time{}
+	for _, ir := range entry.inRecords {
+		if ir.expiry.After(entry.expiry) {
+			entry.expiry = ir.expiry
+		}
+	}
+	heap.Fix(&p.queue, entry.heapIndex)
+}
+
+// Erase deletes a PIT entry, and inserts Nonces of forwarded Interests into the dead Nonce list.
+func (p *pit) Erase(entry *pitEntry) {
+	heap.Remove(&p.queue, entry.heapIndex)
+	if entry.retryTimer != nil {
+		entry.retryTimer.Stop()
+	}
+	delete(p.byKey, entry.key)
+	delete(p.byToken, entry.token)
+	for _, or := range entry.outRecords {
+		p.dnl.Insert(entry.interest.Name, or.nonce)
+	}
+}
+
+// Expire erases PIT entries that have expired.
+func (p *pit) Expire(now time.Time) {
+	for len(p.queue) > 0 && !p.queue[0].expiry.After(now) {
+		p.Erase(p.queue[0])
+	}
+}
+
+// NextExpiry returns the earliest expiration time among PIT entries.
+func (p *pit) NextExpiry() (t time.Time, ok bool) {
+	if len(p.queue) == 0 {
+		return t, false
+	}
+	return p.queue[0].expiry, true
+}
+
+func makePitKey(interest ndn.Interest) string {
+	nameV, _ := interest.Name.MarshalBinary()
+	var flags byte
+	if interest.CanBePrefix {
+		flags |= 0x01
+	}
+	if interest.MustBeFresh {
+		flags |= 0x02
+	}
+	return string(append(nameV, flags))
+}
+
+// pitExpiryQueue is a min-heap of PIT entries ordered by expiration time.
+type pitExpiryQueue []*pitEntry
+
+func (q pitExpiryQueue) Len() int {
+	return len(q)
+}
+
+func (q pitExpiryQueue) Less(i, j int) bool {
+	return q[i].expiry.Before(q[j].expiry)
+}
+
+func (q pitExpiryQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].heapIndex = i
+	q[j].heapIndex = j
+}
+
+func (q *pitExpiryQueue) Push(x interface{}) {
+	entry := x.(*pitEntry)
+	entry.heapIndex = len(*q)
+	*q = append(*q, entry)
+}
+
+func (q *pitExpiryQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	entry := old[n-1]
+	old[n-1] = nil
+	*q = old[:n-1]
+	return entry
+}
+
+// deadNonceList records Name and Nonce of recently satisfied or expired Interests, for loop detection.
+type deadNonceList struct {
+	lifetime time.Duration
+	records  map[string]bool
+	queue    *list.List // *deadNonceRecord in insertion order
+}
+
+type deadNonceRecord struct {
+	key    string
+	expiry time.Time
+}
+
+func newDeadNonceList(lifetime time.Duration) *deadNonceList {
+	return &deadNonceList{
+		lifetime: lifetime,
+		records:  make(map[string]bool),
+		queue:    list.New(),
+	}
+}
+
+// Insert adds a Name and Nonce.
+func (dnl *deadNonceList) Insert(name ndn.Name, nonce ndn.Nonce) {
+	now := time.Now()
+	dnl.expire(now)
+	key := makeDeadNonceKey(name, nonce)
+	if dnl.records[key] {
+		return
+	}
+	dnl.records[key] = true
+	dnl.queue.PushBack(&deadNonceRecord{key, now.Add(dnl.lifetime)})
+}
+
+// Has determines whether a Name and Nonce exists.
+func (dnl *deadNonceList) Has(name ndn.Name, nonce ndn.Nonce) bool {
+	dnl.expire(time.Now())
+	return dnl.records[makeDeadNonceKey(name, nonce)]
+}
+
+func (dnl *deadNonceList) expire(now time.Time) {
+	for elem := dnl.queue.Front(); elem != nil; elem = dnl.queue.Front() {
+		record := elem.Value.(*deadNonceRecord)
+		if record.expiry.After(now) {
+			return
+		}
+		delete(dnl.records, record.key)
+		dnl.queue.Remove(elem)
+	}
+}
+
+func makeDeadNonceKey(name ndn.Name, nonce ndn.Nonce) string {
+	nameV, _ := name.MarshalBinary()
+	return string(append(nameV, nonce[:]...))
+}
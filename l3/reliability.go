@@ -0,0 +1,388 @@
+package l3
+
+import (
+	"container/list"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/eric135/go-ndn"
+	"github.com/eric135/go-ndn/tlv"
+	"github.com/eric135/go-ndn/util"
+)
+
+// ReliabilityConfig contains link-layer reliability configuration.
+type ReliabilityConfig struct {
+	TransportQueueConfig
+
+	// MaxRetx is the maximum number of retransmissions of a frame, after which the frame is considered lost.
+	// The default is 3.
+	MaxRetx int
+
+	// AckDelay is the maximum duration an Ack may wait to be piggybacked on an outgoing frame.
+	// After this duration, pending Acks are sent in IDLE packets.
+	// The default is 5ms.
+	AckDelay time.Duration
+
+	// MaxPiggybackAcks is the maximum number of Acks attached to one frame.
+	// The default is 16.
+	MaxPiggybackAcks int
+
+	// Rtt contains RTT estimator parameters.
+	Rtt util.RttEstimator
+}
+
+func (cfg *ReliabilityConfig) applyDefaults() {
+	cfg.ApplyTransportQueueConfigDefaults()
+	if cfg.MaxRetx <= 0 {
+		cfg.MaxRetx = 3
+	}
+	if cfg.AckDelay <= 0 {
+		cfg.AckDelay = 5 * time.Millisecond
+	}
+	if cfg.MaxPiggybackAcks <= 0 {
+		cfg.MaxPiggybackAcks = 16
+	}
+}
+
+// ReliabilityCounters contains link-layer reliability counters.
+type ReliabilityCounters struct {
+	// NTxFrames is the number of frames sent for the first time.
+	NTxFrames uint64 `json:"nTxFrames"`
+
+	// NRetransmitted is the number of retransmissions.
+	NRetransmitted uint64 `json:"nRetransmitted"`
+
+	// NAcked is the number of frames acknowledged by the peer.
+	NAcked uint64 `json:"nAcked"`
+
+	// NLost is the number of frames given up after exceeding MaxRetx.
+	NLost uint64 `json:"nLost"`
+
+	// NRxFrames is the number of received frames carrying a payload.
+	NRxFrames uint64 `json:"nRxFrames"`
+
+	// NRxDuplicates is the number of received frames dropped as duplicates.
+	NRxDuplicates uint64 `json:"nRxDuplicates"`
+
+	// NIdleAcks is the number of IDLE packets sent to carry Acks.
+	NIdleAcks uint64 `json:"nIdleAcks"`
+
+	// Srtt is the smoothed round-trip time.
+	Srtt time.Duration `json:"srtt"`
+
+	// Rto is the current retransmission timeout.
+	Rto time.Duration `json:"rto"`
+}
+
+// ReliableTransport is a Transport that provides NDNLPv2 link-layer reliability.
+type ReliableTransport interface {
+	Transport
+
+	// Counters returns current counters.
+	Counters() ReliabilityCounters
+}
+
+// NewReliableTransport wraps a Transport with NDNLPv2 link-layer reliability, similar to NFD's BEFD.
+// inner.Rx() and inner.Tx() should not be used after this operation.
+//
+// Each outgoing frame is assigned a TxSequence, and is retransmitted if it is not acknowledged within RTO.
+// The RTO is estimated from acknowledged frames that have not been retransmitted.
+// Acks for incoming frames are piggybacked on outgoing frames, or sent in IDLE packets if there is no outgoing traffic.
+//
+// If inner implements TransportMTU, the returned transport implements TransportMTU with room reserved for reliability fields.
+func NewReliableTransport(inner Transport, cfg ReliabilityConfig) ReliableTransport {
+	cfg.applyDefaults()
+	r := &reliableTransport{
+		inner:      inner,
+		cfg:        cfg,
+		rtte:       cfg.Rtt,
+		nextSeq:    rand.Uint64(),
+		nextTxSeq:  rand.Uint64(),
+		unacked:    make(map[uint64]*unackedFrame),
+		unackedQ:   list.New(),
+		recentSeqs: make(map[uint64]bool),
+	}
+	r.TransportBase, r.p = NewTransportBase(cfg.TransportQueueConfig)
+	r.p.SetState(inner.State())
+	r.stateCloser = inner.OnStateChange(func(st TransportState) {
+		if st != TransportClosed {
+			r.p.SetState(st)
+		}
+	})
+	go r.loop()
+
+	if _, ok := inner.(TransportMTU); ok {
+		return &reliableTransportMTU{r}
+	}
+	return r
+}
+
+type unackedFrame struct {
+	frame *ndn.LpPacket
+	txSeq uint64
+	sent  time.Time
+	nRetx int
+	elem  *list.Element
+}
+
+type reliableTransport struct {
+	*TransportBase
+	p           *TransportBasePriv
+	inner       Transport
+	stateCloser interface{ Close() error }
+	cfg         ReliabilityConfig
+
+	cntMu sync.Mutex
+	cnt   ReliabilityCounters
+	rtte  util.RttEstimator
+
+	nextSeq     uint64
+	nextTxSeq   uint64
+	unacked     map[uint64]*unackedFrame // by TxSequence
+	unackedQ    *list.List               // *unackedFrame in transmission order
+	pendingAcks []uint64
+	recentSeqs  map[uint64]bool
+	recentRing  []uint64
+}
+
+func (r *reliableTransport) Counters() ReliabilityCounters {
+	r.cntMu.Lock()
+	defer r.cntMu.Unlock()
+	return r.cnt
+}
+
+// IsLocal implements TransportLocal.
+func (r *reliableTransport) IsLocal() bool {
+	trLocal, ok := r.inner.(TransportLocal)
+	return ok && trLocal.IsLocal()
+}
+
+// IsMultiAccess implements TransportMultiAccess.
+func (r *reliableTransport) IsMultiAccess() bool {
+	trMultiAccess, ok := r.inner.(TransportMultiAccess)
+	return ok && trMultiAccess.IsMultiAccess()
+}
+
+func (r *reliableTransport) count(fn func(cnt *ReliabilityCounters)) {
+	r.cntMu.Lock()
+	defer r.cntMu.Unlock()
+	fn(&r.cnt)
+}
+
+func (r *reliableTransport) loop() {
+	innerRx, innerTx, tx := r.inner.Rx(), r.inner.Tx(), r.p.Tx
+	retxTimer := time.NewTimer(time.Hour)
+	ackTimer := time.NewTimer(time.Hour)
+	retxTimer.Stop()
+	ackTimer.Stop()
+	ackTimerRunning := false
+
+	for innerRx != nil || tx != nil {
+		select {
+		case wire, ok := <-innerRx:
+			if !ok {
+				innerRx = nil
+				close(r.p.Rx)
+				continue
+			}
+			r.receive(wire)
+			if len(r.pendingAcks) > 0 && !ackTimerRunning {
+				ackTimer.Reset(r.cfg.AckDelay)
+				ackTimerRunning = true
+			}
+		case wire, ok := <-tx:
+			if !ok {
+				tx = nil
+				close(innerTx)
+				continue
+			}
+			if innerRx == nil { // inner transport is gone
+				continue
+			}
+			r.send(innerTx, wire)
+		case <-ackTimer.C:
+			ackTimerRunning = false
+			if innerRx != nil && tx != nil {
+				r.sendIdleAcks(innerTx)
+			}
+		case <-retxTimer.C:
+			if tx != nil {
+				r.retransmit(innerTx)
+			}
+		}
+
+		retxTimer.Stop()
+		if front := r.unackedQ.Front(); front != nil && tx != nil {
+			retxTimer.Reset(time.Until(front.Value.(*unackedFrame).sent.Add(r.rtte.Rto())))
+		}
+	}
+
+	r.stateCloser.Close()
+	r.p.SetState(TransportClosed)
+}
+
+func (r *reliableTransport) send(innerTx chan<- []byte, wire []byte) {
+	var frame ndn.LpPacket
+	if e := tlv.Decode(wire, &frame); e != nil {
+		return
+	}
+	if !frame.Sequence.HasValue {
+		frame.Sequence.Set(r.nextSeq)
+		r.nextSeq++
+	}
+
+	uf := &unackedFrame{frame: &frame}
+	if r.transmit(innerTx, uf) {
+		r.count(func(cnt *ReliabilityCounters) { cnt.NTxFrames++ })
+	}
+}
+
+func (r *reliableTransport) transmit(innerTx chan<- []byte, uf *unackedFrame) bool {
+	uf.txSeq = r.nextTxSeq
+	r.nextTxSeq++
+	uf.frame.TxSequence.Set(uf.txSeq)
+	uf.frame.Acks = r.takeAcks()
+
+	wire, e := tlv.Encode(uf.frame)
+	uf.frame.Acks = nil
+	if e != nil {
+		return false
+	}
+	innerTx <- wire
+
+	uf.sent = time.Now()
+	r.unacked[uf.txSeq] = uf
+	uf.elem = r.unackedQ.PushBack(uf)
+	return true
+}
+
+func (r *reliableTransport) retransmit(innerTx chan<- []byte) {
+	now, rto := time.Now(), r.rtte.Rto()
+	var expired []*unackedFrame
+	for elem := r.unackedQ.Front(); elem != nil; elem = r.unackedQ.Front() {
+		uf := elem.Value.(*unackedFrame)
+		if uf.sent.Add(rto).After(now) {
+			break
+		}
+		r.forget(uf)
+		expired = append(expired, uf)
+	}
+	if len(expired) == 0 {
+		return
+	}
+
+	r.rtte.Backoff()
+	for _, uf := range expired {
+		if uf.nRetx >= r.cfg.MaxRetx {
+			r.count(func(cnt *ReliabilityCounters) { cnt.NLost++ })
+			continue
+		}
+		uf.nRetx++
+		if r.transmit(innerTx, uf) {
+			r.count(func(cnt *ReliabilityCounters) {
+				cnt.NRetransmitted++
+				cnt.Rto = r.rtte.Rto()
+			})
+		}
+	}
+}
+
+func (r *reliableTransport) forget(uf *unackedFrame) {
+	delete(r.unacked, uf.txSeq)
+	r.unackedQ.Remove(uf.elem)
+}
+
+func (r *reliableTransport) takeAcks() (acks []uint64) {
+	n := len(r.pendingAcks)
+	if n > r.cfg.MaxPiggybackAcks {
+		n = r.cfg.MaxPiggybackAcks
+	}
+	acks = append(acks, r.pendingAcks[:n]...)
+	r.pendingAcks = r.pendingAcks[n:]
+	return acks
+}
+
+func (r *reliableTransport) sendIdleAcks(innerTx chan<- []byte) {
+	for len(r.pendingAcks) > 0 {
+		frame := ndn.MakeLpPacket()
+		frame.Acks = r.takeAcks()
+		wire, e := tlv.Encode(frame)
+		if e != nil {
+			return
+		}
+		innerTx <- wire
+		r.count(func(cnt *ReliabilityCounters) { cnt.NIdleAcks++ })
+	}
+}
+
+func (r *reliableTransport) receive(wire []byte) {
+	var frame ndn.LpPacket
+	if e := tlv.Decode(wire, &frame); e != nil {
+		return
+	}
+
+	now := time.Now()
+	for _, ack := range frame.Acks {
+		uf := r.unacked[ack]
+		if uf == nil {
+			continue
+		}
+		r.forget(uf)
+		if uf.nRetx == 0 {
+			r.rtte.AddMeasurement(now.Sub(uf.sent))
+		}
+		r.count(func(cnt *ReliabilityCounters) {
+			cnt.NAcked++
+			cnt.Srtt, cnt.Rto = r.rtte.Srtt(), r.rtte.Rto()
+		})
+	}
+
+	if frame.TxSequence.HasValue {
+		r.pendingAcks = append(r.pendingAcks, frame.TxSequence.Value.(uint64))
+	}
+
+	if !frame.IsFragment() && frame.LpFragment.Interest == nil && frame.LpFragment.Data == nil && frame.LpFragment.Nack == nil { // IDLE packet
+		return
+	}
+	if frame.Sequence.HasValue && r.isDuplicate(frame.Sequence.Value.(uint64)) {
+		r.count(func(cnt *ReliabilityCounters) { cnt.NRxDuplicates++ })
+		return
+	}
+	r.count(func(cnt *ReliabilityCounters) { cnt.NRxFrames++ })
+	r.p.Rx <- wire
+}
+
+// isDuplicate determines whether a Sequence has been received recently, and records it.
+func (r *reliableTransport) isDuplicate(seq uint64) bool {
+	if r.recentSeqs[seq] {
+		return true
+	}
+	if len(r.recentRing) >= reliabilityRecentSeqs {
+		delete(r.recentSeqs, r.recentRing[0])
+		r.recentRing = r.recentRing[1:]
+	}
+	r.recentSeqs[seq] = true
+	r.recentRing = append(r.recentRing, seq)
+	return false
+}
+
+const reliabilityRecentSeqs = 1024
+
+type reliableTransportMTU struct {
+	*reliableTransport
+}
+
+// MTU implements TransportMTU.
+func (r *reliableTransportMTU) MTU() int {
+	return r.inner.(TransportMTU).MTU() - reliabilityOverhead(r.cfg.MaxPiggybackAcks)
+}
+
+func reliabilityOverhead(maxAcks int) int {
+	const (
+		sizeofSequence   = 1 + 1 + 8
+		sizeofTxSequence = 3 + 1 + 8
+		sizeofAck        = 3 + 1 + 8
+	)
+	return sizeofSequence + sizeofTxSequence + maxAcks*sizeofAck
+}
@@ -0,0 +1,97 @@
+package l3_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/eric135/go-ndn"
+	"github.com/eric135/go-ndn/l3"
+	"github.com/eric135/go-ndn/util"
+)
+
+func TestReliability(t *testing.T) {
+	assert, require := makeAR(t)
+
+	pipeA, pipeB := l3.NewMemoryTransportPair(l3.MemoryTransportConfig{LossRate: 0.2, Seed: 1})
+	cfg := l3.ReliabilityConfig{
+		MaxRetx: 8,
+		Rtt: util.RttEstimator{
+			InitialRto: 20 * time.Millisecond,
+			MinRto:     20 * time.Millisecond,
+			MaxRto:     100 * time.Millisecond,
+		},
+	}
+	trA, trB := l3.NewReliableTransport(pipeA, cfg), l3.NewReliableTransport(pipeB, cfg)
+	faceA, e := l3.NewFace(trA)
+	require.NoError(e)
+	faceB, e := l3.NewFace(trB)
+	require.NoError(e)
+
+	const count = 200
+	go func() {
+		for i := 0; i < count; i++ {
+			interest := ndn.MakeInterest(fmt.Sprintf("/A/%d", i))
+			faceA.Tx() <- interest
+		}
+	}()
+
+	received := make(map[string]bool)
+	timeout := time.After(5 * time.Second)
+L:
+	for len(received) < count {
+		select {
+		case pkt := <-faceB.Rx():
+			require.NotNil(pkt.Interest)
+			received[pkt.Interest.Name.String()] = true
+		case <-timeout:
+			break L
+		}
+	}
+	assert.Len(received, count)
+
+	// wait for outstanding frames to be acknowledged
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if cnt := trA.Counters(); cnt.NAcked+cnt.NLost >= count {
+			break
+		}
+	}
+	cntA, cntB := trA.Counters(), trB.Counters()
+	assert.EqualValues(count, cntA.NTxFrames)
+	assert.Greater(cntA.NRetransmitted, uint64(0))
+	assert.Greater(cntA.NAcked, uint64(count*9/10))
+	assert.Greater(cntB.NIdleAcks, uint64(0))
+	assert.EqualValues(count, cntB.NRxFrames)
+	assert.NotZero(cntA.Srtt)
+
+	close(faceA.Tx())
+	close(faceB.Tx())
+}
+
+type localTransport struct {
+	l3.Transport
+}
+
+func (localTransport) IsLocal() bool {
+	return true
+}
+
+func TestReliabilityWrapperInterfaces(t *testing.T) {
+	assert, _ := makeAR(t)
+
+	pipeA, pipeB := l3.NewMemoryTransportPair(l3.MemoryTransportConfig{MultiAccess: true})
+	trA := l3.NewReliableTransport(pipeA, l3.ReliabilityConfig{})
+	defer close(trA.Tx())
+	trB := l3.NewReliableTransport(localTransport{pipeB}, l3.ReliabilityConfig{})
+	defer close(trB.Tx())
+
+	if trMultiAccess, ok := trA.(l3.TransportMultiAccess); assert.True(ok) {
+		assert.True(trMultiAccess.IsMultiAccess())
+	}
+	if trLocal, ok := trA.(l3.TransportLocal); assert.True(ok) {
+		assert.False(trLocal.IsLocal())
+	}
+	if trLocal, ok := trB.(l3.TransportLocal); assert.True(ok) {
+		assert.True(trLocal.IsLocal())
+	}
+}
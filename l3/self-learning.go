@@ -0,0 +1,137 @@
+package l3
+
+import (
+	"time"
+
+	"github.com/eric135/go-ndn"
+)
+
+// learnedRoute is a route learned from a prefix announcement.
+type learnedRoute struct {
+	name  ndn.Name
+	timer *time.Timer
+}
+
+// isSelfLearning determines whether a name is under SelfLearningStrategy.
+func (fw *forwarder) isSelfLearning(name ndn.Name) bool {
+	_, ok := fw.findStrategy(name).(selfLearningStrategy)
+	return ok
+}
+
+// pitSelectNexthops chooses upstream faces for a PIT entry.
+//
+// Under SelfLearningStrategy, if there is a route, the Interest is forwarded as a non-discovery Interest.
+// Otherwise, a discovery Interest is broadcast to multi-access faces, while a non-discovery Interest has no upstream.
+func (fw *forwarder) pitSelectNexthops(entry *pitEntry, downstreams []*fwFace, tried []FwFace) []*fwFace {
+	candidates := fw.lpm(entry.interest.Name, downstreams...)
+	if !fw.isSelfLearning(entry.interest.Name) {
+		return fw.selectNexthops(entry.interest, candidates, tried)
+	}
+
+	if len(candidates) > 0 {
+		entry.nonDiscovery = true
+		return fw.selectNexthops(entry.interest, candidates, tried)
+	}
+	if len(tried) > 0 || !entry.HasDiscoveryInRecord() {
+		return nil
+	}
+	entry.nonDiscovery = false
+	return fw.broadcastNexthops(downstreams)
+}
+
+// broadcastNexthops returns non-local multi-access faces, excluding downstream faces.
+func (fw *forwarder) broadcastNexthops(downstreams []*fwFace) (upstreams []*fwFace) {
+	for _, f := range fw.faces {
+		if f.multiAccess && !f.local && !containsFwFace(downstreams, f) {
+			upstreams = append(upstreams, f)
+		}
+	}
+	return upstreams
+}
+
+// slProcessData handles prefix announcements on Data under SelfLearningStrategy.
+// If the Data carries a prefix announcement, a route is learned toward the face it arrived on.
+// If the Data comes from a local producer, a prefix announcement is generated from the producer's announcements.
+// Returns the prefix announcement to be attached to Data sent to downstreams of discovery Interests, or nil.
+func (fw *forwarder) slProcessData(pkt fwPacket) *ndn.Data {
+	name := pkt.Data.Name
+	if !fw.isSelfLearning(name) {
+		return nil
+	}
+
+	if pa := pkt.Lp.SelfLearning.PrefixAnnouncement; pa != nil {
+		if !fw.learnRoute(pkt.face, *pa, name) {
+			return nil
+		}
+		return pa
+	}
+
+	if pkt.face.local {
+		return fw.makeAnnouncement(pkt.face, name)
+	}
+	return nil
+}
+
+// learnRoute adds or refreshes a route according to a prefix announcement.
+// The announced prefix must be a prefix of the Data name and must be under SelfLearningStrategy.
+// Prefix announcements are not verified.
+func (fw *forwarder) learnRoute(f *fwFace, data ndn.Data, name ndn.Name) bool {
+	pa, e := ndn.ParsePrefixAnnouncement(data)
+	if e != nil || !pa.Prefix.IsPrefixOf(name) || !fw.isSelfLearning(pa.Prefix) {
+		return false
+	}
+	lifetime := pa.EffectiveExpiration(time.Now())
+	if lifetime <= 0 {
+		return false
+	}
+
+	nameV, _ := pa.Prefix.MarshalBinary()
+	nameS := string(nameV)
+	if old := f.learned[nameS]; old != nil {
+		old.timer.Stop()
+	}
+	if _, ok := f.routes[nameS]; !ok {
+		fw.fib.Insert(pa.Prefix, f, 0)
+	}
+
+	lr := &learnedRoute{name: pa.Prefix}
+	lr.timer = time.AfterFunc(lifetime, func() {
+		fw.cmd <- func() {
+			if f.learned[nameS] != lr {
+				return
+			}
+			delete(f.learned, nameS)
+			if _, ok := f.routes[nameS]; !ok && fw.isActive(f) {
+				fw.fib.Remove(lr.name, f)
+			}
+		}
+	})
+	f.learned[nameS] = lr
+	return true
+}
+
+// makeAnnouncement returns a prefix announcement for the longest announced prefix of a local producer face that matches name.
+// Generated prefix announcements are cached until the prefix is no longer announced.
+func (fw *forwarder) makeAnnouncement(f *fwFace, name ndn.Name) *ndn.Data {
+	var prefix ndn.Name
+	var prefixS string
+	found := false
+	for nameS, announced := range f.announcements {
+		if announced.IsPrefixOf(name) && (!found || len(announced) > len(prefix)) {
+			prefix, prefixS, found = announced, nameS, true
+		}
+	}
+	if !found {
+		return nil
+	}
+
+	if pa := fw.paCache[prefixS]; pa != nil {
+		return pa
+	}
+	pa, e := ndn.MakePrefixAnnouncement(prefix, fw.cfg.SelfLearningExpiration, nil, fw.cfg.SelfLearningSigner)
+	if e != nil {
+		return nil
+	}
+	fw.paCache[prefixS] = &pa
+	return &pa
+}
@@ -0,0 +1,200 @@
+package l3_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/eric135/go-ndn"
+	"github.com/eric135/go-ndn/an"
+	"github.com/eric135/go-ndn/endpoint"
+	"github.com/eric135/go-ndn/l3"
+	"github.com/stretchr/testify/require"
+)
+
+// addMultiAccessFace adds a multi-access face to the forwarder, and returns the FwFace and the other end of the face.
+func addMultiAccessFace(require *require.Assertions, fw l3.Forwarder) (l3.FwFace, l3.Face) {
+	trA, trB := l3.NewMemoryTransportPair(l3.MemoryTransportConfig{MultiAccess: true})
+	ff, e := fw.AddTransport(trA)
+	require.NoError(e)
+	face, e := l3.NewFace(trB)
+	require.NoError(e)
+	return ff, face
+}
+
+func TestSelfLearningDiscovery(t *testing.T) {
+	assert, require := makeAR(t)
+
+	fw := l3.NewForwarderWithConfig(l3.ForwarderConfig{
+		EnablePit:       true,
+		DefaultStrategy: l3.SelfLearningStrategy,
+	})
+	_, faceC := addTestFace(require, fw)
+	defer close(faceC.Tx())
+	_, faceM1 := addMultiAccessFace(require, fw)
+	defer close(faceM1.Tx())
+	ffM2, faceM2 := addMultiAccessFace(require, fw)
+	defer close(faceM2.Tx())
+
+	// discovery Interest is broadcast on multi-access faces
+	faceC.Tx() <- ndn.MakeInterest("/P/1", makeTokenLpL3([]byte{0xC1}))
+	pkt1 := recvPacket(faceM1)
+	require.NotNil(pkt1)
+	require.NotNil(pkt1.Interest)
+	assert.False(pkt1.Lp.SelfLearning.NonDiscovery)
+	pkt := recvPacket(faceM2)
+	require.NotNil(pkt)
+	require.NotNil(pkt.Interest)
+	nameEqual(assert, "/P/1", pkt.Interest)
+
+	// Data with prefix announcement creates a route
+	pa, e := ndn.MakePrefixAnnouncement(ndn.ParseName("/P"), time.Minute, nil, ndn.DigestSigning)
+	require.NoError(e)
+	data := ndn.MakeData(pkt.Interest)
+	reply := &ndn.Packet{Data: &data}
+	reply.Lp.PitToken = pkt.Lp.PitToken
+	reply.Lp.SelfLearning.PrefixAnnouncement = &pa
+	faceM2.Tx() <- reply
+
+	pkt = recvPacket(faceC)
+	require.NotNil(pkt)
+	require.NotNil(pkt.Data)
+	assert.Equal([]byte{0xC1}, pkt.Lp.PitToken)
+	require.NotNil(pkt.Lp.SelfLearning.PrefixAnnouncement)
+	nameEqual(assert, pa.Name, pkt.Lp.SelfLearning.PrefixAnnouncement)
+
+	entries := fw.Fib().Entries()
+	require.Len(entries, 1)
+	nameEqual(assert, "/P", entries[0].Name)
+	require.Len(entries[0].Nexthops, 1)
+	assert.Equal(ffM2, entries[0].Nexthops[0].Face)
+
+	// Interest matching learned route is forwarded as non-discovery Interest
+	faceC.Tx() <- ndn.MakeInterest("/P/2", makeTokenLpL3([]byte{0xC2}))
+	pkt = recvPacket(faceM2)
+	require.NotNil(pkt)
+	require.NotNil(pkt.Interest)
+	assert.True(pkt.Lp.SelfLearning.NonDiscovery)
+	assert.Nil(recvPacket(faceM1))
+	faceM2.Tx() <- ndn.MakeData(pkt.Interest, makeTokenLpL3(pkt.Lp.PitToken))
+	pkt = recvPacket(faceC)
+	require.NotNil(pkt)
+	require.NotNil(pkt.Data)
+	assert.Nil(pkt.Lp.SelfLearning.PrefixAnnouncement)
+
+	// refreshing learned route does not change static route cost
+	ffM2.AddRoute(ndn.ParseName("/P"), 5)
+	faceC.Tx() <- ndn.MakeInterest("/P/3", makeTokenLpL3([]byte{0xC3}))
+	pkt = recvPacket(faceM2)
+	require.NotNil(pkt)
+	require.NotNil(pkt.Interest)
+	data = ndn.MakeData(pkt.Interest)
+	reply = &ndn.Packet{Data: &data}
+	reply.Lp.PitToken = pkt.Lp.PitToken
+	reply.Lp.SelfLearning.PrefixAnnouncement = &pa
+	faceM2.Tx() <- reply
+	pkt = recvPacket(faceC)
+	require.NotNil(pkt)
+	require.NotNil(pkt.Data)
+	entries = fw.Fib().Entries()
+	require.Len(entries, 1)
+	require.Len(entries[0].Nexthops, 1)
+	assert.Equal(5, entries[0].Nexthops[0].Cost)
+
+	// removing static route does not remove learned route
+	ffM2.RemoveRoute(ndn.ParseName("/P"))
+	entries = fw.Fib().Entries()
+	require.Len(entries, 1)
+	require.Len(entries[0].Nexthops, 1)
+	assert.Equal(ffM2, entries[0].Nexthops[0].Face)
+	assert.Equal(0, entries[0].Nexthops[0].Cost)
+
+	// non-discovery Interest without route is Nacked
+	interest := ndn.MakeInterest("/Q", makeTokenLpL3([]byte{0xC4}))
+	ipkt := interest.ToPacket()
+	ipkt.Lp.SelfLearning.NonDiscovery = true
+	faceC.Tx() <- ipkt
+	pkt = recvPacket(faceC)
+	require.NotNil(pkt)
+	require.NotNil(pkt.Nack)
+	assert.EqualValues(an.NackNoRoute, pkt.Nack.Reason)
+
+	// learned route expires
+	faceC.Tx() <- ndn.MakeInterest("/R/1", makeTokenLpL3([]byte{0xC5}))
+	pkt = recvPacket(faceM1)
+	require.NotNil(pkt)
+	require.NotNil(pkt.Interest)
+	assert.NotNil(recvPacket(faceM2))
+	paR, e := ndn.MakePrefixAnnouncement(ndn.ParseName("/R"), 100*time.Millisecond, nil, ndn.DigestSigning)
+	require.NoError(e)
+	data = ndn.MakeData(pkt.Interest)
+	reply = &ndn.Packet{Data: &data}
+	reply.Lp.PitToken = pkt.Lp.PitToken
+	reply.Lp.SelfLearning.PrefixAnnouncement = &paR
+	faceM1.Tx() <- reply
+	pkt = recvPacket(faceC)
+	require.NotNil(pkt)
+	require.NotNil(pkt.Data)
+
+	hasRoute := func(name string) bool {
+		for _, entry := range fw.Fib().Entries() {
+			if entry.Name.Equal(ndn.ParseName(name)) {
+				return true
+			}
+		}
+		return false
+	}
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if !hasRoute("/R") {
+			break
+		}
+	}
+	assert.False(hasRoute("/R"))
+	assert.True(hasRoute("/P"))
+}
+
+func TestSelfLearningProducer(t *testing.T) {
+	assert, require := makeAR(t)
+
+	fw := l3.NewForwarderWithConfig(l3.ForwarderConfig{
+		EnablePit:              true,
+		DefaultStrategy:        l3.SelfLearningStrategy,
+		SelfLearningExpiration: 10 * time.Minute,
+	})
+	_, faceD := addMultiAccessFace(require, fw)
+	defer close(faceD.Tx())
+
+	p, e := endpoint.Produce(context.Background(), endpoint.ProducerOptions{
+		Prefix: ndn.ParseName("/L"),
+		Fw:     fw,
+		Handler: func(ctx context.Context, interest ndn.Interest) (ndn.Data, error) {
+			return ndn.MakeData(interest), nil
+		},
+	})
+	require.NoError(e)
+	defer p.Close()
+
+	// reply to discovery Interest carries prefix announcement
+	faceD.Tx() <- ndn.MakeInterest("/L/1", makeTokenLpL3([]byte{0xD1}))
+	pkt := recvPacket(faceD)
+	require.NotNil(pkt)
+	require.NotNil(pkt.Data)
+	nameEqual(assert, "/L/1", pkt.Data)
+	require.NotNil(pkt.Lp.SelfLearning.PrefixAnnouncement)
+	pa, e := ndn.ParsePrefixAnnouncement(*pkt.Lp.SelfLearning.PrefixAnnouncement)
+	require.NoError(e)
+	nameEqual(assert, "/L", pa.Prefix)
+	assert.Equal(10*time.Minute, pa.ExpirationPeriod)
+	assert.NoError(ndn.DigestSigning.Verify(*pkt.Lp.SelfLearning.PrefixAnnouncement))
+
+	// reply to non-discovery Interest does not carry prefix announcement
+	interest := ndn.MakeInterest("/L/2", makeTokenLpL3([]byte{0xD2}))
+	ipkt := interest.ToPacket()
+	ipkt.Lp.SelfLearning.NonDiscovery = true
+	faceD.Tx() <- ipkt
+	pkt = recvPacket(faceD)
+	require.NotNil(pkt)
+	require.NotNil(pkt.Data)
+	nameEqual(assert, "/L/2", pkt.Data)
+	assert.Nil(pkt.Lp.SelfLearning.PrefixAnnouncement)
+}
@@ -0,0 +1,128 @@
+package l3
+
+import (
+	"math/rand"
+
+	"github.com/eric135/go-ndn"
+)
+
+// Nexthop represents a candidate upstream face of an Interest.
+type Nexthop struct {
+	Face FwFace
+	Cost int
+}
+
+// Strategy decides where to forward Interests.
+//
+// A strategy is selected for each Interest by longest prefix match in the strategy choice table.
+// Its methods are invoked on the forwarder goroutine, and should not block.
+type Strategy interface {
+	// SelectNexthops chooses the upstream faces for an Interest.
+	//
+	// candidates contains nexthops of the longest prefix matched routes, excluding the downstream face,
+	// sorted by increasing cost.
+	//
+	// tried contains upstream faces that the Interest has been forwarded to.
+	// It is non-empty only if the forwarder has a PIT, and the Interest has timed out or has been Nacked on every tried face.
+	// Returning no face at this point means the strategy gives up on the Interest.
+	SelectNexthops(interest ndn.Interest, candidates []Nexthop, tried []FwFace) []FwFace
+}
+
+// Built-in strategies.
+var (
+	// BestRouteStrategy forwards an Interest to the lowest-cost nexthop.
+	// If it times out or is Nacked, the Interest is retried on the next lowest-cost nexthop that has not been tried.
+	BestRouteStrategy Strategy = bestRouteStrategy{}
+
+	// MulticastStrategy forwards an Interest to every nexthop.
+	MulticastStrategy Strategy = multicastStrategy{}
+
+	// RandomStrategy forwards an Interest to a randomly chosen nexthop, for load balancing.
+	RandomStrategy Strategy = randomStrategy{}
+
+	// SelfLearningStrategy forwards an Interest to every nexthop, and learns routes from prefix announcements.
+	// If there is no route, a discovery Interest is broadcast to multi-access faces.
+	// See Forwarder for details.
+	SelfLearningStrategy Strategy = selfLearningStrategy{}
+)
+
+type bestRouteStrategy struct{}
+
+func (bestRouteStrategy) SelectNexthops(interest ndn.Interest, candidates []Nexthop, tried []FwFace) []FwFace {
+	for _, nh := range candidates {
+		if !containsFace(tried, nh.Face) {
+			return []FwFace{nh.Face}
+		}
+	}
+	return nil
+}
+
+type multicastStrategy struct{}
+
+func (multicastStrategy) SelectNexthops(interest ndn.Interest, candidates []Nexthop, tried []FwFace) (faces []FwFace) {
+	if len(tried) > 0 {
+		return nil
+	}
+	for _, nh := range candidates {
+		faces = append(faces, nh.Face)
+	}
+	return faces
+}
+
+type randomStrategy struct{}
+
+func (randomStrategy) SelectNexthops(interest ndn.Interest, candidates []Nexthop, tried []FwFace) []FwFace {
+	if len(tried) > 0 || len(candidates) == 0 {
+		return nil
+	}
+	return []FwFace{candidates[rand.Intn(len(candidates))].Face}
+}
+
+type selfLearningStrategy struct {
+	multicastStrategy
+}
+
+func (fw *forwarder) SetStrategy(prefix ndn.Name, strategy Strategy) {
+	prefixV, _ := prefix.MarshalBinary()
+	fw.execute(func() {
+		fw.strategies[string(prefixV)] = strategy
+	})
+}
+
+func (fw *forwarder) UnsetStrategy(prefix ndn.Name) {
+	prefixV, _ := prefix.MarshalBinary()
+	fw.execute(func() {
+		delete(fw.strategies, string(prefixV))
+	})
+}
+
+// findStrategy finds the strategy for a name by longest prefix match in the strategy choice table.
+func (fw *forwarder) findStrategy(name ndn.Name) Strategy {
+	if len(fw.strategies) > 0 {
+		for i := len(name); i >= 0; i-- {
+			prefixV, _ := name.GetPrefix(i).MarshalBinary()
+			if strategy := fw.strategies[string(prefixV)]; strategy != nil {
+				return strategy
+			}
+		}
+	}
+	return fw.cfg.DefaultStrategy
+}
+
+func containsFace(faces []FwFace, f FwFace) bool {
+	for _, face := range faces {
+		if face == f {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFwFace(faces []*fwFace, f *fwFace) bool {
+	for _, face := range faces {
+		if face == f {
+			return true
+		}
+	}
+	return false
+}
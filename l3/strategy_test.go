@@ -0,0 +1,121 @@
+package l3_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/eric135/go-ndn"
+	"github.com/eric135/go-ndn/an"
+	"github.com/eric135/go-ndn/l3"
+)
+
+func TestStrategyBestRoute(t *testing.T) {
+	assert, require := makeAR(t)
+
+	fw := l3.NewForwarderWithConfig(l3.ForwarderConfig{
+		EnablePit:       true,
+		RetryTimeout:    100 * time.Millisecond,
+		DefaultStrategy: l3.BestRouteStrategy,
+	})
+	_, faceA := addTestFace(require, fw)
+	defer close(faceA.Tx())
+	ffU1, faceU1 := addTestFace(require, fw)
+	defer close(faceU1.Tx())
+	ffU2, faceU2 := addTestFace(require, fw)
+	defer close(faceU2.Tx())
+	ffU1.AddRoute(ndn.ParseName("/"), 10)
+	ffU2.AddRoute(ndn.ParseName("/"), 20)
+
+	// retry after Nack
+	faceA.Tx() <- ndn.MakeInterest("/N", makeTokenLpL3([]byte{0xA0}))
+	pkt := recvPacket(faceU1)
+	require.NotNil(pkt)
+	require.NotNil(pkt.Interest)
+	faceU1.Tx() <- ndn.MakeNack(pkt.Interest, an.NackCongestion)
+	pkt = recvPacket(faceU2)
+	require.NotNil(pkt)
+	require.NotNil(pkt.Interest)
+	nameEqual(assert, "/N", pkt.Interest)
+	faceU2.Tx() <- ndn.MakeNack(pkt.Interest, an.NackNoRoute)
+	pkt = recvPacket(faceA)
+	require.NotNil(pkt)
+	require.NotNil(pkt.Nack)
+	assert.EqualValues(an.NackCongestion, pkt.Nack.Reason)
+
+	// retry after timeout
+	faceA.Tx() <- ndn.MakeInterest("/T", makeTokenLpL3([]byte{0xA1}))
+	pkt = recvPacket(faceU1)
+	require.NotNil(pkt)
+	require.NotNil(pkt.Interest)
+	pkt = recvPacket(faceU2)
+	require.NotNil(pkt)
+	require.NotNil(pkt.Interest)
+	nameEqual(assert, "/T", pkt.Interest)
+	faceU2.Tx() <- ndn.MakeData(pkt.Interest, makeTokenLpL3(pkt.Lp.PitToken))
+	pkt = recvPacket(faceA)
+	require.NotNil(pkt)
+	require.NotNil(pkt.Data)
+	assert.Equal([]byte{0xA1}, pkt.Lp.PitToken)
+	assert.Nil(recvPacket(faceU1))
+
+	// longest prefix match takes precedence over cost
+	ffU2.AddRoute(ndn.ParseName("/L"), 30)
+	faceA.Tx() <- ndn.MakeInterest("/L/1", makeTokenLpL3([]byte{0xA2}))
+	pkt = recvPacket(faceU2)
+	require.NotNil(pkt)
+	require.NotNil(pkt.Interest)
+	nameEqual(assert, "/L/1", pkt.Interest)
+	assert.Nil(recvPacket(faceU1))
+}
+
+func TestStrategyChoice(t *testing.T) {
+	assert, require := makeAR(t)
+
+	fw := l3.NewForwarder()
+	_, faceA := addTestFace(require, fw)
+	defer close(faceA.Tx())
+	ffU1, faceU1 := addTestFace(require, fw)
+	defer close(faceU1.Tx())
+	ffU2, faceU2 := addTestFace(require, fw)
+	defer close(faceU2.Tx())
+	ffU1.AddRoute(ndn.ParseName("/"), 10)
+	ffU2.AddRoute(ndn.ParseName("/"), 20)
+	fw.SetStrategy(ndn.ParseName("/B"), l3.BestRouteStrategy)
+	fw.SetStrategy(ndn.ParseName("/R"), l3.RandomStrategy)
+
+	// default is multicast
+	faceA.Tx() <- ndn.MakeInterest("/M")
+	assert.NotNil(recvPacket(faceU1))
+	assert.NotNil(recvPacket(faceU2))
+
+	faceA.Tx() <- ndn.MakeInterest("/B/1")
+	assert.NotNil(recvPacket(faceU1))
+	assert.Nil(recvPacket(faceU2))
+
+	nReceived := 0
+	for i := 0; i < 10; i++ {
+		faceA.Tx() <- ndn.MakeInterest("/R/1")
+		for _, face := range []l3.Face{faceU1, faceU2} {
+			select {
+			case <-face.Rx():
+				nReceived++
+			case <-time.After(20 * time.Millisecond):
+			}
+		}
+	}
+	assert.Equal(10, nReceived)
+
+	// Interest is not returned to the downstream face, even if it has a matching route
+	faceU1.Tx() <- ndn.MakeInterest("/B/3")
+	assert.NotNil(recvPacket(faceU2))
+	assert.Nil(recvPacket(faceU1))
+
+	fw.UnsetStrategy(ndn.ParseName("/B"))
+	faceA.Tx() <- ndn.MakeInterest("/B/2")
+	assert.NotNil(recvPacket(faceU1))
+	assert.NotNil(recvPacket(faceU2))
+
+	faceU2.Tx() <- ndn.MakeInterest("/M/1")
+	assert.NotNil(recvPacket(faceU1))
+	assert.Nil(recvPacket(faceU2))
+}
@@ -0,0 +1,295 @@
+// Package stream implements Transports over stream-oriented sockets, such as TCP and Unix sockets.
+//
+// The byte stream is split into TLV elements, so that each element received from Rx() is a complete TLV element.
+package stream
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/eric135/go-ndn/l3"
+	"github.com/eric135/go-ndn/tlv"
+)
+
+// Error conditions.
+var (
+	ErrElementSize = errors.New("TLV element exceeds maximum size")
+)
+
+// Config contains stream transport configuration.
+type Config struct {
+	l3.TransportQueueConfig
+
+	// MaxElementSize is the maximum size of an incoming TLV element.
+	// If a larger element is received, the connection is considered broken.
+	// The default is DefaultMaxElementSize.
+	MaxElementSize int
+
+	// RedialBackoffInitial is the initial backoff period during redialing.
+	// The default is DefaultRedialBackoffInitial.
+	RedialBackoffInitial time.Duration
+
+	// RedialBackoffMaximum is the maximum backoff period during redialing.
+	// The default is DefaultRedialBackoffMaximum.
+	// The minimum is RedialBackoffInitial.
+	RedialBackoffMaximum time.Duration
+}
+
+func (cfg *Config) applyDefaults() {
+	cfg.ApplyTransportQueueConfigDefaults()
+	if cfg.MaxElementSize <= 0 {
+		cfg.MaxElementSize = DefaultMaxElementSize
+	}
+	if cfg.RedialBackoffInitial <= 0 {
+		cfg.RedialBackoffInitial = DefaultRedialBackoffInitial
+	}
+	if cfg.RedialBackoffMaximum <= 0 {
+		cfg.RedialBackoffMaximum = DefaultRedialBackoffMaximum
+	}
+	if cfg.RedialBackoffMaximum < cfg.RedialBackoffInitial {
+		cfg.RedialBackoffMaximum = cfg.RedialBackoffInitial
+	}
+}
+
+// Config defaults.
+const (
+	DefaultMaxElementSize       = 8800
+	DefaultRedialBackoffInitial = 100 * time.Millisecond
+	DefaultRedialBackoffMaximum = 60 * time.Second
+)
+
+// Counters contains stream transport counters.
+type Counters struct {
+	// NRedials is the number of redial attempts.
+	NRedials int `json:"nRedials"`
+}
+
+// Transport is an l3.Transport that communicates over a stream socket.
+//
+// If a socket error occurs, the transport enters TransportDown state.
+// A transport created by Dial then redials the socket with exponential backoff, and returns to TransportUp state upon success.
+// A transport created by New cannot redial, and remains in TransportDown state.
+// Packets sent while the transport is down are dropped.
+//
+// The transport closes itself, and enters TransportClosed state, only after its TX channel has been closed.
+type Transport interface {
+	l3.Transport
+
+	// Conn returns the underlying socket.
+	// Caller may gather information from this socket, but should not close or send/receive on it.
+	// The socket may be replaced during redialing.
+	Conn() net.Conn
+
+	// Counters returns current counters.
+	Counters() Counters
+}
+
+// Dial connects to a stream socket and creates a transport.
+// network should be "tcp", "tcp4", "tcp6", or "unix".
+func Dial(network, address string, cfg Config) (Transport, error) {
+	conn, e := net.Dial(network, address)
+	if e != nil {
+		return nil, e
+	}
+	return newTransport(conn, network, address, cfg), nil
+}
+
+// New creates a transport from an established connection, such as an accepted socket.
+func New(conn net.Conn, cfg Config) Transport {
+	return newTransport(conn, "", "", cfg)
+}
+
+type connError struct {
+	conn net.Conn
+	e    error
+}
+
+type transport struct {
+	*l3.TransportBase
+	p       *l3.TransportBasePriv
+	cfg     Config
+	network string // empty if redial is not possible
+	address string
+	closing chan struct{}
+	err     chan connError
+
+	mutex sync.Mutex
+	conn  net.Conn
+	cnt   Counters
+}
+
+func newTransport(conn net.Conn, network, address string, cfg Config) *transport {
+	cfg.applyDefaults()
+	tr := &transport{
+		cfg:     cfg,
+		network: network,
+		address: address,
+		closing: make(chan struct{}),
+		err:     make(chan connError, 1),
+		conn:    conn,
+	}
+	tr.TransportBase, tr.p = l3.NewTransportBase(cfg.TransportQueueConfig)
+	go tr.connLoop()
+	go tr.txLoop()
+	return tr
+}
+
+func (tr *transport) Conn() net.Conn {
+	tr.mutex.Lock()
+	defer tr.mutex.Unlock()
+	return tr.conn
+}
+
+func (tr *transport) Counters() Counters {
+	tr.mutex.Lock()
+	defer tr.mutex.Unlock()
+	return tr.cnt
+}
+
+// IsLocal implements l3.TransportLocal.
+func (tr *transport) IsLocal() bool {
+	switch raddr := tr.Conn().RemoteAddr().(type) {
+	case *net.UnixAddr:
+		return true
+	case *net.TCPAddr:
+		return raddr.IP.IsLoopback()
+	}
+	return false
+}
+
+// connLoop manages the connection, including receiving and redialing.
+func (tr *transport) connLoop() {
+	conn := tr.Conn()
+	go tr.rxLoop(conn)
+
+	for {
+		var ce connError
+		select {
+		case <-tr.closing:
+			conn.Close()
+			for ce = range tr.err { // wait for rxLoop to exit
+				if ce.conn == conn {
+					break
+				}
+			}
+			close(tr.p.Rx)
+			tr.p.SetState(l3.TransportClosed)
+			return
+		case ce = <-tr.err:
+		}
+		if ce.conn != conn { // error from previous connection
+			continue
+		}
+
+		tr.p.SetState(l3.TransportDown)
+		conn.Close()
+		if newConn := tr.redial(); newConn != nil {
+			conn = newConn
+			tr.mutex.Lock()
+			tr.conn = conn
+			tr.mutex.Unlock()
+			go tr.rxLoop(conn)
+			tr.p.SetState(l3.TransportUp)
+		} else { // rxLoop has exited, as it reported the error
+			<-tr.closing
+			close(tr.p.Rx)
+			tr.p.SetState(l3.TransportClosed)
+			return
+		}
+	}
+}
+
+// redial attempts to reestablish the connection with exponential backoff.
+// Returns nil if the transport cannot redial or is closing.
+func (tr *transport) redial() net.Conn {
+	if tr.network == "" {
+		return nil
+	}
+	for backoff := tr.cfg.RedialBackoffInitial; ; backoff *= 2 {
+		if backoff > tr.cfg.RedialBackoffMaximum {
+			backoff = tr.cfg.RedialBackoffMaximum
+		}
+		select {
+		case <-tr.closing:
+			return nil
+		case <-time.After(backoff):
+		}
+
+		tr.mutex.Lock()
+		tr.cnt.NRedials++
+		tr.mutex.Unlock()
+		if conn, e := net.Dial(tr.network, tr.address); e == nil {
+			return conn
+		}
+	}
+}
+
+func (tr *transport) rxLoop(conn net.Conn) {
+	buffer := make([]byte, tr.cfg.MaxElementSize)
+	nAvail := 0
+	for {
+		nRead, e := conn.Read(buffer[nAvail:])
+		if e != nil {
+			tr.err <- connError{conn, e}
+			return
+		}
+		nAvail += nRead
+
+		rest := buffer[:nAvail]
+		for {
+			size, e := elementSize(rest)
+			if errors.Is(e, tlv.ErrIncomplete) {
+				break
+			}
+			if e == nil && size > tr.cfg.MaxElementSize {
+				e = ErrElementSize
+			}
+			if e != nil {
+				conn.Close()
+				tr.err <- connError{conn, e}
+				return
+			}
+			if size > len(rest) {
+				break
+			}
+
+			wire := make([]byte, size)
+			copy(wire, rest)
+			tr.p.Rx <- wire
+			rest = rest[size:]
+		}
+		nAvail = copy(buffer, rest)
+	}
+}
+
+func (tr *transport) txLoop() {
+	for wire := range tr.p.Tx {
+		conn := tr.Conn()
+		if _, e := conn.Write(wire); e != nil {
+			conn.Close() // rxLoop reports the error
+		}
+	}
+	close(tr.closing)
+}
+
+// elementSize determines the size of the TLV element at the front of wire.
+func elementSize(wire []byte) (size int, e error) {
+	var typ, length tlv.VarNum
+	rest, e := typ.Decode(wire)
+	if e != nil {
+		return 0, e
+	}
+	if typ == 0 {
+		return 0, tlv.ErrType
+	}
+	if rest, e = length.Decode(rest); e != nil {
+		return 0, e
+	}
+	headerSize := len(wire) - len(rest)
+	if uint64(length) > uint64(1<<31-1-headerSize) {
+		return 0, ErrElementSize
+	}
+	return headerSize + int(length), nil
+}
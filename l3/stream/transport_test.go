@@ -0,0 +1,168 @@
+package stream_test
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/eric135/go-ndn/l3"
+	"github.com/eric135/go-ndn/l3/stream"
+	"github.com/eric135/go-ndn/ndntestenv"
+)
+
+func checkStream(t *testing.T, network, address string) {
+	assert, require := makeAR(t)
+
+	listener, e := net.Listen(network, address)
+	require.NoError(e)
+	defer listener.Close()
+	accepted := make(chan net.Conn)
+	go func() {
+		conn, e := listener.Accept()
+		require.NoError(e)
+		accepted <- conn
+	}()
+
+	trA, e := stream.Dial(network, listener.Addr().String(), stream.Config{})
+	require.NoError(e)
+	trB := stream.New(<-accepted, stream.Config{})
+	assert.True(trA.(l3.TransportLocal).IsLocal())
+
+	var c ndntestenv.L3FaceTester
+	c.CheckTransport(t, trA, trB)
+}
+
+func TestTcp(t *testing.T) {
+	checkStream(t, "tcp", "127.0.0.1:0")
+}
+
+func TestUnix(t *testing.T) {
+	_, require := makeAR(t)
+	dir, e := ioutil.TempDir("", "stream-test")
+	require.NoError(e)
+	defer os.RemoveAll(dir)
+
+	checkStream(t, "unix", filepath.Join(dir, "unix.sock"))
+}
+
+func TestFraming(t *testing.T) {
+	assert, _ := makeAR(t)
+
+	connA, connB := net.Pipe()
+	tr := stream.New(connA, stream.Config{MaxElementSize: 300})
+	defer close(tr.Tx())
+
+	go func() {
+		connB.Write([]byte{0x05, 0x02, 0xA0})
+		connB.Write([]byte{0xA1, 0x06, 0x01, 0xB0, 0x07})
+		connB.Write([]byte{0x00, 0x08, 0xFD})
+		connB.Write([]byte{0x01, 0x40})
+	}()
+
+	for _, expected := range [][]byte{
+		{0x05, 0x02, 0xA0, 0xA1},
+		{0x06, 0x01, 0xB0},
+		{0x07, 0x00},
+	} {
+		select {
+		case wire := <-tr.Rx():
+			assert.Equal(expected, wire)
+		case <-time.After(time.Second):
+			assert.Fail("element not received")
+			return
+		}
+	}
+
+	// 0x08 element with TLV-LENGTH 320 exceeds MaxElementSize
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(l3.TransportDown, tr.State())
+}
+
+func TestPeerClose(t *testing.T) {
+	assert, _ := makeAR(t)
+
+	connA, connB := net.Pipe()
+	tr := stream.New(connA, stream.Config{})
+	states := make(chan l3.TransportState, 4)
+	tr.OnStateChange(func(st l3.TransportState) { states <- st })
+
+	connB.Close()
+	select {
+	case st := <-states:
+		assert.Equal(l3.TransportDown, st)
+	case <-time.After(time.Second):
+		assert.Fail("transport not down")
+	}
+
+	close(tr.Tx())
+	select {
+	case _, ok := <-tr.Rx():
+		assert.False(ok)
+	case <-time.After(time.Second):
+		assert.Fail("Rx not closed")
+	}
+	select {
+	case st := <-states:
+		assert.Equal(l3.TransportClosed, st)
+	case <-time.After(time.Second):
+		assert.Fail("transport not closed")
+	}
+}
+
+func TestRedial(t *testing.T) {
+	assert, require := makeAR(t)
+
+	listener, e := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(e)
+	defer listener.Close()
+	accepted := make(chan net.Conn, 2)
+	go func() {
+		for {
+			conn, e := listener.Accept()
+			if e != nil {
+				return
+			}
+			accepted <- conn
+		}
+	}()
+
+	tr, e := stream.Dial("tcp", listener.Addr().String(), stream.Config{
+		RedialBackoffInitial: 10 * time.Millisecond,
+	})
+	require.NoError(e)
+	states := make(chan l3.TransportState, 4)
+	tr.OnStateChange(func(st l3.TransportState) { states <- st })
+
+	conn := <-accepted
+	conn.Close()
+
+	for _, expected := range []l3.TransportState{l3.TransportDown, l3.TransportUp} {
+		select {
+		case st := <-states:
+			assert.Equal(expected, st)
+		case <-time.After(time.Second):
+			assert.Fail("state not changed", "expecting %v", expected)
+		}
+	}
+	assert.GreaterOrEqual(tr.Counters().NRedials, 1)
+
+	conn = <-accepted
+	defer conn.Close()
+	tr.Tx() <- []byte{0x05, 0x02, 0xC0, 0xC1}
+	buf := make([]byte, 64)
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	n, e := conn.Read(buf)
+	require.NoError(e)
+	assert.Equal([]byte{0x05, 0x02, 0xC0, 0xC1}, buf[:n])
+
+	close(tr.Tx())
+	select {
+	case st := <-states:
+		assert.Equal(l3.TransportClosed, st)
+	case <-time.After(time.Second):
+		assert.Fail("transport not closed")
+	}
+}
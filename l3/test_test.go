@@ -0,0 +1,11 @@
+package l3_test
+
+import (
+	"github.com/eric135/go-ndn/ndntestenv"
+	"github.com/usnistgov/ndn-dpdk/core/testenv"
+)
+
+var (
+	makeAR    = testenv.MakeAR
+	nameEqual = ndntestenv.NameEqual
+)
@@ -2,6 +2,7 @@ package l3
 
 import (
 	"io"
+	"sync"
 
 	"github.com/usnistgov/ndn-dpdk/core/events"
 )
@@ -14,6 +15,7 @@ const (
 type TransportBase struct {
 	rx      <-chan []byte
 	tx      chan<- []byte
+	stateMu sync.Mutex
 	state   TransportState
 	emitter *events.Emitter
 }
@@ -30,6 +32,8 @@ func (b *TransportBase) Tx() chan<- []byte {
 
 // State implements Transport.
 func (b *TransportBase) State() TransportState {
+	b.stateMu.Lock()
+	defer b.stateMu.Unlock()
 	return b.state
 }
 
@@ -46,11 +50,16 @@ type TransportBasePriv struct {
 }
 
 // SetState changes transport state.
+// It is safe to call this function from multiple goroutines.
+// Once the state is TransportClosed, it cannot be changed.
 func (p *TransportBasePriv) SetState(st TransportState) {
-	if p.b.state == st {
+	p.b.stateMu.Lock()
+	if p.b.state == st || p.b.state == TransportClosed {
+		p.b.stateMu.Unlock()
 		return
 	}
 	p.b.state = st
+	p.b.stateMu.Unlock()
 	p.b.emitter.EmitSync(evtStateChange, st)
 }
 
@@ -24,6 +24,27 @@ type Transport interface {
 	OnStateChange(cb func(st TransportState)) io.Closer
 }
 
+// TransportMTU is an optional interface implemented by a Transport with a limited maximum transmission unit.
+// Face fragments outgoing packets so that each TLV element passed to Tx() fits within the MTU.
+type TransportMTU interface {
+	// MTU returns the maximum size of an outgoing TLV element.
+	MTU() int
+}
+
+// TransportLocal is an optional interface implemented by a Transport that communicates with local applications.
+// Forwarder does not decrement HopLimit of Interests sent to a local transport.
+type TransportLocal interface {
+	// IsLocal returns true if the remote endpoint is on the local host.
+	IsLocal() bool
+}
+
+// TransportMultiAccess is an optional interface implemented by a Transport that reaches multiple peers, such as a multicast group.
+// Under SelfLearningStrategy, Forwarder broadcasts discovery Interests to multi-access transports.
+type TransportMultiAccess interface {
+	// IsMultiAccess returns true if packets sent on this transport may reach multiple peers.
+	IsMultiAccess() bool
+}
+
 // TransportQueueConfig defaults.
 const (
 	DefaultTransportRxQueueSize = 64
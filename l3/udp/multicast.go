@@ -0,0 +1,28 @@
+package udp
+
+import (
+	"net"
+)
+
+// NewMulticastTransport creates a UDP multicast transport.
+// ifname is the network interface name.
+// The multicast group is specified in cfg.MulticastGroup, which defaults to the NDN multicast group.
+//
+// Outgoing packets are sent to the multicast group from the same socket that receives packets from the group.
+func NewMulticastTransport(ifname string, cfg Config) (Transport, error) {
+	cfg.applyDefaults()
+	ifi, e := net.InterfaceByName(ifname)
+	if e != nil {
+		return nil, e
+	}
+	group, e := net.ResolveUDPAddr("udp4", cfg.MulticastGroup)
+	if e != nil {
+		return nil, e
+	}
+
+	conn, e := net.ListenMulticastUDP("udp4", ifi, group)
+	if e != nil {
+		return nil, e
+	}
+	return newTransport(conn, group, cfg), nil
+}
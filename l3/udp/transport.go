@@ -0,0 +1,176 @@
+// Package udp implements Transports over UDP unicast and multicast sockets.
+//
+// Each datagram carries exactly one TLV element.
+// Incoming datagrams that do not contain exactly one TLV element are dropped.
+package udp
+
+import (
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/eric135/go-ndn/l3"
+	"github.com/eric135/go-ndn/tlv"
+)
+
+// Config contains UDP transport configuration.
+type Config struct {
+	l3.TransportQueueConfig
+
+	// MTU is the maximum size of an outgoing datagram.
+	// The default is DefaultMTU.
+	MTU int
+
+	// MulticastGroup is the multicast group address and port.
+	// This is effective only in NewMulticastTransport.
+	// The default is DefaultMulticastGroup.
+	MulticastGroup string
+}
+
+func (cfg *Config) applyDefaults() {
+	cfg.ApplyTransportQueueConfigDefaults()
+	if cfg.MTU <= 0 {
+		cfg.MTU = DefaultMTU
+	}
+	if cfg.MulticastGroup == "" {
+		cfg.MulticastGroup = DefaultMulticastGroup
+	}
+}
+
+// Config defaults.
+const (
+	DefaultMTU            = 8800
+	DefaultMulticastGroup = "224.0.23.170:56363"
+)
+
+// Transport is an l3.Transport that communicates over a UDP socket.
+//
+// If a socket error occurs, the transport enters TransportDown state.
+// It returns to TransportUp state after the next successful send or receive operation.
+// The transport closes itself after its TX channel has been closed.
+type Transport interface {
+	l3.Transport
+	l3.TransportMTU
+	l3.TransportMultiAccess
+
+	// Conn returns the underlying socket.
+	// Caller may gather information from this socket, but should not close or send/receive on it.
+	Conn() *net.UDPConn
+}
+
+// NewTransport creates a UDP unicast transport.
+// local is the local address, which may be empty to select an ephemeral port.
+// remote is the remote address.
+func NewTransport(local, remote string, cfg Config) (Transport, error) {
+	raddr, e := net.ResolveUDPAddr("udp", remote)
+	if e != nil {
+		return nil, e
+	}
+	var laddr *net.UDPAddr
+	if local != "" {
+		if laddr, e = net.ResolveUDPAddr("udp", local); e != nil {
+			return nil, e
+		}
+	}
+
+	conn, e := net.DialUDP("udp", laddr, raddr)
+	if e != nil {
+		return nil, e
+	}
+	return newTransport(conn, nil, cfg), nil
+}
+
+type transport struct {
+	*l3.TransportBase
+	p      *l3.TransportBasePriv
+	cfg    Config
+	conn   *net.UDPConn
+	dest   *net.UDPAddr // nil for connected socket
+	closed int32        // atomic bool
+}
+
+func newTransport(conn *net.UDPConn, dest *net.UDPAddr, cfg Config) *transport {
+	cfg.applyDefaults()
+	tr := &transport{
+		cfg:  cfg,
+		conn: conn,
+		dest: dest,
+	}
+	tr.TransportBase, tr.p = l3.NewTransportBase(cfg.TransportQueueConfig)
+	go tr.rxLoop()
+	go tr.txLoop()
+	return tr
+}
+
+func (tr *transport) Conn() *net.UDPConn {
+	return tr.conn
+}
+
+func (tr *transport) MTU() int {
+	return tr.cfg.MTU
+}
+
+// IsLocal implements l3.TransportLocal.
+func (tr *transport) IsLocal() bool {
+	raddr, ok := tr.conn.RemoteAddr().(*net.UDPAddr)
+	return ok && raddr.IP.IsLoopback()
+}
+
+// IsMultiAccess implements l3.TransportMultiAccess.
+func (tr *transport) IsMultiAccess() bool {
+	return tr.dest != nil
+}
+
+func (tr *transport) isClosed() bool {
+	return atomic.LoadInt32(&tr.closed) != 0
+}
+
+func (tr *transport) rxLoop() {
+	buffer := make([]byte, maxDatagramSize)
+	for {
+		n, e := tr.conn.Read(buffer)
+		if e != nil {
+			if tr.isClosed() {
+				break
+			}
+			tr.p.SetState(l3.TransportDown)
+			time.Sleep(errorBackoff)
+			continue
+		}
+		tr.p.SetState(l3.TransportUp)
+
+		var element tlv.Element
+		if rest, e := element.Decode(buffer[:n]); e != nil || len(rest) > 0 {
+			continue
+		}
+		wire := make([]byte, n)
+		copy(wire, buffer)
+		tr.p.Rx <- wire
+	}
+	close(tr.p.Rx)
+	tr.p.SetState(l3.TransportClosed)
+}
+
+func (tr *transport) txLoop() {
+	for wire := range tr.p.Tx {
+		var e error
+		if tr.dest == nil {
+			_, e = tr.conn.Write(wire)
+		} else {
+			_, e = tr.conn.WriteToUDP(wire, tr.dest)
+		}
+
+		if e != nil {
+			tr.p.SetState(l3.TransportDown)
+		} else {
+			tr.p.SetState(l3.TransportUp)
+		}
+	}
+	atomic.StoreInt32(&tr.closed, 1)
+	tr.conn.Close()
+}
+
+const (
+	maxDatagramSize = 65535
+	errorBackoff    = 10 * time.Millisecond
+)
@@ -0,0 +1,97 @@
+package udp_test
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/eric135/go-ndn/l3"
+	"github.com/eric135/go-ndn/l3/udp"
+	"github.com/eric135/go-ndn/ndntestenv"
+)
+
+func TestUnicast(t *testing.T) {
+	assert, require := makeAR(t)
+
+	trA, e := udp.NewTransport("127.0.0.1:7001", "127.0.0.1:7002", udp.Config{})
+	require.NoError(e)
+	trB, e := udp.NewTransport("127.0.0.1:7002", "127.0.0.1:7001", udp.Config{})
+	require.NoError(e)
+	assert.Equal(udp.DefaultMTU, trA.MTU())
+	assert.True(trA.(l3.TransportLocal).IsLocal())
+
+	var c ndntestenv.L3FaceTester
+	c.CheckTransport(t, trA, trB)
+}
+
+func TestUnicastDown(t *testing.T) {
+	assert, require := makeAR(t)
+
+	tr, e := udp.NewTransport("127.0.0.1:7003", "127.0.0.1:7004", udp.Config{})
+	require.NoError(e)
+	assert.Equal(l3.TransportUp, tr.State())
+
+	// nobody listens on remote port, ICMP port unreachable causes socket error
+	tr.Tx() <- []byte{0x05, 0x00}
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(l3.TransportDown, tr.State())
+
+	closed := make(chan bool)
+	tr.OnStateChange(func(st l3.TransportState) {
+		if st == l3.TransportClosed {
+			close(closed)
+		}
+	})
+	close(tr.Tx())
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		assert.Fail("transport not closed")
+	}
+}
+
+func TestMulticast(t *testing.T) {
+	assert, require := makeAR(t)
+
+	ifi, e := net.InterfaceByName("lo")
+	if e != nil {
+		t.Skip("loopback interface not found")
+	}
+	cfg := udp.Config{MulticastGroup: "224.0.23.170:7005"}
+	tr, e := udp.NewMulticastTransport(ifi.Name, cfg)
+	if e != nil {
+		t.Skipf("cannot create multicast transport: %v", e)
+	}
+	defer close(tr.Tx())
+
+	// a second socket on the same group observes packets sent by the transport
+	group, _ := net.ResolveUDPAddr("udp4", cfg.MulticastGroup)
+	peer, e := net.ListenMulticastUDP("udp4", ifi, group)
+	require.NoError(e)
+	defer peer.Close()
+
+	tr.Tx() <- []byte{0x05, 0x02, 0xF0, 0xF1}
+	peer.SetReadDeadline(time.Now().Add(time.Second))
+	buf := make([]byte, 64)
+	n, e := peer.Read(buf)
+	if e != nil {
+		t.Skipf("multicast loopback unavailable: %v", e)
+	}
+	assert.Equal([]byte{0x05, 0x02, 0xF0, 0xF1}, buf[:n])
+
+	_, e = peer.WriteToUDP([]byte{0x06, 0x02, 0xE0, 0xE1}, group)
+	require.NoError(e)
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case wire := <-tr.Rx():
+			if wire[0] == 0x06 {
+				assert.Equal([]byte{0x06, 0x02, 0xE0, 0xE1}, wire)
+				return
+			}
+		case <-timeout:
+			assert.Fail("packet not received")
+			return
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package websocket
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+
+	gorilla "github.com/gorilla/websocket"
+)
+
+// Error conditions.
+var (
+	ErrListenerClosed = errors.New("listener closed")
+)
+
+// Listener is an http.Handler that accepts WebSocket connections.
+// It should be registered on an HTTP server, and then Accept() yields a Transport for each accepted connection.
+type Listener struct {
+	cfg      Config
+	upgrader gorilla.Upgrader
+	accepted chan Transport
+	closing  chan struct{}
+	close    sync.Once
+}
+
+var _ http.Handler = (*Listener)(nil)
+
+// NewListener creates a Listener.
+func NewListener(cfg Config) *Listener {
+	cfg.applyDefaults()
+	return &Listener{
+		cfg: cfg,
+		upgrader: gorilla.Upgrader{
+			CheckOrigin: cfg.CheckOrigin,
+		},
+		accepted: make(chan Transport),
+		closing:  make(chan struct{}),
+	}
+}
+
+// ServeHTTP upgrades an HTTP request to WebSocket, and passes the transport to Accept().
+func (l *Listener) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	select {
+	case <-l.closing:
+		http.Error(w, ErrListenerClosed.Error(), http.StatusServiceUnavailable)
+		return
+	default:
+	}
+
+	conn, e := l.upgrader.Upgrade(w, r, nil)
+	if e != nil {
+		return
+	}
+	tr := New(conn, l.cfg)
+	select {
+	case l.accepted <- tr:
+	case <-l.closing:
+		close(tr.Tx())
+	}
+}
+
+// Accept waits for and returns the next accepted transport.
+func (l *Listener) Accept() (Transport, error) {
+	select {
+	case tr := <-l.accepted:
+		return tr, nil
+	case <-l.closing:
+		return nil, ErrListenerClosed
+	}
+}
+
+// Close stops accepting connections.
+// Transports already returned by Accept() are unaffected.
+func (l *Listener) Close() error {
+	l.close.Do(func() { close(l.closing) })
+	return nil
+}
@@ -0,0 +1,135 @@
+// Package websocket implements Transports over WebSocket connections.
+//
+// Each binary message carries exactly one TLV element.
+// Incoming messages that are not binary or do not contain exactly one TLV element are dropped.
+package websocket
+
+import (
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/eric135/go-ndn/l3"
+	"github.com/eric135/go-ndn/tlv"
+	gorilla "github.com/gorilla/websocket"
+)
+
+// Config contains WebSocket transport configuration.
+type Config struct {
+	l3.TransportQueueConfig
+
+	// MaxMessageSize is the maximum size of an incoming message.
+	// If a larger message is received, the connection is considered broken.
+	// The default is DefaultMaxMessageSize.
+	MaxMessageSize int
+
+	// CheckOrigin determines whether to accept a connection request from a browser.
+	// This is effective only in Listener.
+	// The default accepts same-origin requests only.
+	CheckOrigin func(r *http.Request) bool
+}
+
+func (cfg *Config) applyDefaults() {
+	cfg.ApplyTransportQueueConfigDefaults()
+	if cfg.MaxMessageSize <= 0 {
+		cfg.MaxMessageSize = DefaultMaxMessageSize
+	}
+}
+
+// Config defaults.
+const (
+	DefaultMaxMessageSize = 8800
+)
+
+// Transport is an l3.Transport that communicates over a WebSocket connection.
+//
+// If the connection fails, the transport enters TransportDown state, and does not recover.
+// Packets sent while the transport is down are dropped.
+// The transport closes itself, and enters TransportClosed state, only after its TX channel has been closed.
+type Transport interface {
+	l3.Transport
+
+	// Conn returns the underlying connection.
+	// Caller may gather information from this connection, but should not close or send/receive on it.
+	Conn() *gorilla.Conn
+}
+
+// Dial connects to a WebSocket server and creates a transport.
+// url should have "ws" or "wss" scheme.
+func Dial(url string, cfg Config) (Transport, error) {
+	conn, _, e := gorilla.DefaultDialer.Dial(url, nil)
+	if e != nil {
+		return nil, e
+	}
+	return New(conn, cfg), nil
+}
+
+// New creates a transport from an established connection.
+func New(conn *gorilla.Conn, cfg Config) Transport {
+	cfg.applyDefaults()
+	tr := &transport{
+		cfg:    cfg,
+		conn:   conn,
+		rxDone: make(chan struct{}),
+	}
+	tr.TransportBase, tr.p = l3.NewTransportBase(cfg.TransportQueueConfig)
+	conn.SetReadLimit(int64(cfg.MaxMessageSize))
+	go tr.rxLoop()
+	go tr.txLoop()
+	return tr
+}
+
+type transport struct {
+	*l3.TransportBase
+	p      *l3.TransportBasePriv
+	cfg    Config
+	conn   *gorilla.Conn
+	rxDone chan struct{}
+}
+
+func (tr *transport) Conn() *gorilla.Conn {
+	return tr.conn
+}
+
+// IsLocal implements l3.TransportLocal.
+func (tr *transport) IsLocal() bool {
+	raddr, ok := tr.conn.RemoteAddr().(*net.TCPAddr)
+	return ok && raddr.IP.IsLoopback()
+}
+
+func (tr *transport) rxLoop() {
+	defer close(tr.rxDone)
+	for {
+		typ, wire, e := tr.conn.ReadMessage()
+		if e != nil {
+			tr.p.SetState(l3.TransportDown)
+			return
+		}
+		if typ != gorilla.BinaryMessage {
+			continue
+		}
+
+		var element tlv.Element
+		if rest, e := element.Decode(wire); e != nil || len(rest) > 0 {
+			continue
+		}
+		tr.p.Rx <- wire
+	}
+}
+
+func (tr *transport) txLoop() {
+	for wire := range tr.p.Tx {
+		if e := tr.conn.WriteMessage(gorilla.BinaryMessage, wire); e != nil {
+			tr.p.SetState(l3.TransportDown)
+		}
+	}
+
+	tr.conn.WriteControl(gorilla.CloseMessage, gorilla.FormatCloseMessage(gorilla.CloseNormalClosure, ""),
+		time.Now().Add(closeTimeout))
+	tr.conn.Close()
+	<-tr.rxDone
+	close(tr.p.Rx)
+	tr.p.SetState(l3.TransportClosed)
+}
+
+const closeTimeout = time.Second
@@ -0,0 +1,78 @@
+package websocket_test
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eric135/go-ndn/l3"
+	"github.com/eric135/go-ndn/l3/websocket"
+	"github.com/eric135/go-ndn/ndntestenv"
+)
+
+func TestTransport(t *testing.T) {
+	assert, require := makeAR(t)
+
+	listener := websocket.NewListener(websocket.Config{})
+	server := httptest.NewServer(listener)
+	defer server.Close()
+	defer listener.Close()
+
+	trA, e := websocket.Dial("ws"+strings.TrimPrefix(server.URL, "http"), websocket.Config{})
+	require.NoError(e)
+	trB, e := listener.Accept()
+	require.NoError(e)
+	assert.True(trA.(l3.TransportLocal).IsLocal())
+
+	var c ndntestenv.L3FaceTester
+	c.CheckTransport(t, trA, trB)
+}
+
+func TestPeerClose(t *testing.T) {
+	assert, require := makeAR(t)
+
+	listener := websocket.NewListener(websocket.Config{})
+	server := httptest.NewServer(listener)
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	trA, e := websocket.Dial(url, websocket.Config{})
+	require.NoError(e)
+	trB, e := listener.Accept()
+	require.NoError(e)
+
+	trA.Tx() <- []byte{0x05, 0x02, 0xA0, 0xA1}
+	trA.Tx() <- []byte{0x05, 0x02, 0xA0} // invalid TLV, dropped
+	close(trA.Tx())
+
+	select {
+	case wire := <-trB.Rx():
+		assert.Equal([]byte{0x05, 0x02, 0xA0, 0xA1}, wire)
+	case <-time.After(time.Second):
+		assert.Fail("packet not received")
+	}
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(l3.TransportDown, trB.State())
+
+	closed := make(chan bool)
+	trB.OnStateChange(func(st l3.TransportState) {
+		if st == l3.TransportClosed {
+			close(closed)
+		}
+	})
+	close(trB.Tx())
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		assert.Fail("transport not closed")
+	}
+	_, ok := <-trB.Rx()
+	assert.False(ok)
+
+	listener.Close()
+	_, e = listener.Accept()
+	assert.Equal(websocket.ErrListenerClosed, e)
+	_, e = websocket.Dial(url, websocket.Config{})
+	assert.Error(e)
+}
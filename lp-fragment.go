@@ -0,0 +1,264 @@
+package ndn
+
+import (
+	"bytes"
+	"container/list"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/eric135/go-ndn/tlv"
+)
+
+// Fragmenter splits network layer packets into NDNLPv2 frames that fit within an MTU.
+type Fragmenter struct {
+	mtu     int
+	nextSeq uint64
+}
+
+// NewFragmenter creates a Fragmenter.
+// mtu is the maximum encoded size of an outgoing LpPacket.
+func NewFragmenter(mtu int) *Fragmenter {
+	return &Fragmenter{
+		mtu:     mtu,
+		nextSeq: rand.Uint64(),
+	}
+}
+
+// Fragment encodes a packet and splits it into LpPackets.
+//
+// Every returned frame is assigned a Sequence number, and fragments of the same packet have consecutive Sequence numbers.
+// If the packet fits in the MTU, a single frame without FragIndex and FragCount is returned.
+// Otherwise, LpL3 fields are placed on the first fragment only.
+func (f *Fragmenter) Fragment(full *Packet) (frames []*LpPacket, e error) {
+	payload, e := full.encodeL3()
+	if e != nil {
+		return nil, e
+	}
+	header, e := tlv.Encode(full.Lp.encode(), full.Lp.SelfLearning.encode())
+	if e != nil {
+		return nil, e
+	}
+
+	room := f.mtu - fragmentOverhead
+	if len(header)+len(payload) <= room { // no fragmentation necessary
+		frame := MakeLpPacket()
+		frame.LpFragment = full
+		frame.Sequence.Set(f.allocSeq())
+		return []*LpPacket{&frame}, nil
+	}
+
+	sizeofFirstFragment := room - len(header)
+	if sizeofFirstFragment <= 0 || room <= 0 { // MTU is too small to fit this packet
+		return nil, ErrFragment
+	}
+
+	fragCount := 1 + (len(payload)-sizeofFirstFragment+room-1)/room
+	if fragCount > math.MaxUint16 {
+		return nil, ErrFragment
+	}
+
+	for offset, nextOffset := 0, sizeofFirstFragment; offset < len(payload); offset, nextOffset = nextOffset, nextOffset+room {
+		if nextOffset > len(payload) {
+			nextOffset = len(payload)
+		}
+
+		frame := MakeLpPacket()
+		if offset == 0 {
+			frame.LpFragment.Lp = full.Lp
+		}
+		frame.Sequence.Set(f.allocSeq())
+		frame.FragIndex = len(frames)
+		frame.FragCount = fragCount
+		frame.FragmentPayload = payload[offset:nextOffset]
+		frames = append(frames, &frame)
+	}
+	return frames, nil
+}
+
+func (f *Fragmenter) allocSeq() (seq uint64) {
+	seq = f.nextSeq
+	f.nextSeq++
+	return seq
+}
+
+const fragmentOverhead = 0 +
+	1 + 3 + // LpPacket TL
+	1 + 1 + 8 + // Sequence
+	1 + 1 + 2 + // FragIndex
+	1 + 1 + 2 + // FragCount
+	1 + 3 + // LpFragment TL
+	0
+
+// ReassemblerConfig contains Reassembler configuration.
+type ReassemblerConfig struct {
+	// Timeout is the maximum duration between the first received fragment and completion of a packet.
+	// The default is DefaultReassemblyTimeout.
+	Timeout time.Duration
+
+	// MaxPartials is the maximum number of partially received packets.
+	// The default is DefaultReassemblyMaxPartials.
+	MaxPartials int
+
+	// MaxBytes is the maximum total size of buffered fragment payloads.
+	// The default is DefaultReassemblyMaxBytes.
+	MaxBytes int
+}
+
+func (cfg *ReassemblerConfig) applyDefaults() {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultReassemblyTimeout
+	}
+	if cfg.MaxPartials <= 0 {
+		cfg.MaxPartials = DefaultReassemblyMaxPartials
+	}
+	if cfg.MaxBytes <= 0 {
+		cfg.MaxBytes = DefaultReassemblyMaxBytes
+	}
+}
+
+// ReassemblerConfig defaults.
+const (
+	DefaultReassemblyTimeout     = 500 * time.Millisecond
+	DefaultReassemblyMaxPartials = 256
+	DefaultReassemblyMaxBytes    = 1 << 22
+)
+
+// Reassembler collects NDNLPv2 fragments and reassembles network layer packets.
+//
+// Fragments are grouped by sequence base, i.e. Sequence minus FragIndex.
+// A partially received packet is discarded when it exceeds the timeout, or when buffer limits are reached,
+// in which case the oldest partial packets are discarded first.
+//
+// This type is non-thread-safe.
+type Reassembler struct {
+	cfg      ReassemblerConfig
+	partials map[uint64]*lpPartial
+	queue    *list.List // *lpPartial in arrival order
+	nBytes   int
+}
+
+type lpPartial struct {
+	seqBase   uint64
+	expiry    time.Time
+	lp        LpL3
+	fragments [][]byte
+	nReceived int
+	size      int
+	elem      *list.Element
+}
+
+// NewReassembler creates a Reassembler.
+func NewReassembler(cfg ReassemblerConfig) *Reassembler {
+	cfg.applyDefaults()
+	return &Reassembler{
+		cfg:      cfg,
+		partials: make(map[uint64]*lpPartial),
+		queue:    list.New(),
+	}
+}
+
+// Accept processes an incoming frame.
+//
+// If the frame is not a fragment, the network layer packet it carries is returned, which is nil for an IDLE packet.
+// If the frame completes a fragmented packet, the reassembled packet is returned.
+// Otherwise, the fragment is buffered and nil is returned.
+func (r *Reassembler) Accept(frame *LpPacket) (*Packet, error) {
+	if !frame.IsFragment() {
+		if pkt := frame.LpFragment; pkt != nil && pkt.hasL3() {
+			return pkt, nil
+		}
+		return nil, nil
+	}
+
+	if !frame.Sequence.HasValue || frame.FragIndex < 0 || frame.FragIndex >= frame.FragCount {
+		return nil, ErrFragment
+	}
+	seqBase := frame.Sequence.Value.(uint64) - uint64(frame.FragIndex)
+	fragSize := len(frame.FragmentPayload)
+	if fragSize > r.cfg.MaxBytes {
+		return nil, ErrFragment
+	}
+
+	now := time.Now()
+	r.expire(now)
+
+	partial := r.partials[seqBase]
+	if partial != nil && len(partial.fragments) != frame.FragCount {
+		r.discard(partial)
+		return nil, ErrFragment
+	}
+	if partial == nil {
+		for len(r.partials) >= r.cfg.MaxPartials {
+			r.discard(r.queue.Front().Value.(*lpPartial))
+		}
+		partial = &lpPartial{
+			seqBase:   seqBase,
+			expiry:    now.Add(r.cfg.Timeout),
+			fragments: make([][]byte, frame.FragCount),
+		}
+		partial.elem = r.queue.PushBack(partial)
+		r.partials[seqBase] = partial
+	}
+
+	if partial.fragments[frame.FragIndex] != nil { // duplicate
+		return nil, nil
+	}
+
+	for r.nBytes+fragSize > r.cfg.MaxBytes {
+		oldest := r.queue.Front().Value.(*lpPartial)
+		if oldest == partial {
+			r.discard(partial)
+			return nil, ErrFragment
+		}
+		r.discard(oldest)
+	}
+
+	partial.fragments[frame.FragIndex] = frame.FragmentPayload
+	partial.nReceived++
+	partial.size += fragSize
+	r.nBytes += fragSize
+	if frame.FragIndex == 0 && frame.LpFragment != nil {
+		partial.lp = frame.LpFragment.Lp
+	}
+
+	if partial.nReceived < len(partial.fragments) {
+		return nil, nil
+	}
+	r.discard(partial)
+	return partial.reassemble()
+}
+
+func (r *Reassembler) expire(now time.Time) {
+	for elem := r.queue.Front(); elem != nil; elem = r.queue.Front() {
+		partial := elem.Value.(*lpPartial)
+		if partial.expiry.After(now) {
+			return
+		}
+		r.discard(partial)
+	}
+}
+
+func (r *Reassembler) discard(partial *lpPartial) {
+	r.queue.Remove(partial.elem)
+	delete(r.partials, partial.seqBase)
+	r.nBytes -= partial.size
+}
+
+func (partial *lpPartial) reassemble() (*Packet, error) {
+	payload := bytes.Join(partial.fragments, nil)
+	d := tlv.Decoder(payload)
+	field, e := d.Element()
+	if e != nil {
+		return nil, e
+	}
+	if e := d.ErrUnlessEOF(); e != nil {
+		return nil, e
+	}
+
+	pkt := &Packet{Lp: partial.lp}
+	if e := pkt.decodeL3(field.Type, field.Value); e != nil {
+		return nil, e
+	}
+	return pkt, nil
+}
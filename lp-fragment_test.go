@@ -0,0 +1,124 @@
+package ndn_test
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/eric135/go-ndn"
+	"github.com/eric135/go-ndn/tlv"
+)
+
+func TestFragmentReassemble(t *testing.T) {
+	assert, require := makeAR(t)
+
+	content := make([]byte, 5000)
+	rand.Read(content)
+	data := ndn.MakeData("/D", content)
+	pkt := data.ToPacket()
+	pkt.Lp.PitToken = ndn.PitTokenFromUint(0xB0B1B2B3B4B5B6B7)
+
+	fragmenter := ndn.NewFragmenter(1200)
+	frames, e := fragmenter.Fragment(pkt)
+	require.NoError(e)
+	require.Len(frames, 5)
+
+	wires := make([][]byte, len(frames))
+	for i, frame := range frames {
+		assert.Equal(i, frame.FragIndex)
+		assert.Equal(len(frames), frame.FragCount)
+		assert.Equal(frames[0].Sequence.Value.(uint64)+uint64(i), frame.Sequence.Value.(uint64))
+
+		wires[i], e = tlv.Encode(frame)
+		require.NoError(e)
+		assert.LessOrEqual(len(wires[i]), 1200)
+	}
+
+	reassembler := ndn.NewReassembler(ndn.ReassemblerConfig{})
+	for n, i := range []int{3, 0, 4, 0, 2, 1} {
+		var frame ndn.LpPacket
+		require.NoError(tlv.Decode(wires[i], &frame))
+		assert.True(frame.IsFragment())
+
+		reassembled, e := reassembler.Accept(&frame)
+		assert.NoError(e)
+		if n < 5 {
+			assert.Nil(reassembled, "%d", n)
+			continue
+		}
+		require.NotNil(reassembled)
+		require.NotNil(reassembled.Data)
+		nameEqual(assert, "/D", reassembled.Data)
+		assert.Equal(content, reassembled.Data.Content)
+		assert.Equal(uint64(0xB0B1B2B3B4B5B6B7), ndn.PitTokenToUint(reassembled.Lp.PitToken))
+	}
+}
+
+func TestFragmentSmall(t *testing.T) {
+	assert, require := makeAR(t)
+
+	interest := ndn.MakeInterest("/I")
+	fragmenter := ndn.NewFragmenter(1200)
+	frames, e := fragmenter.Fragment(interest.ToPacket())
+	require.NoError(e)
+	require.Len(frames, 1)
+	assert.True(frames[0].Sequence.HasValue)
+	assert.False(frames[0].IsFragment())
+
+	wire, e := tlv.Encode(frames[0])
+	require.NoError(e)
+	var frame ndn.LpPacket
+	require.NoError(tlv.Decode(wire, &frame))
+
+	reassembler := ndn.NewReassembler(ndn.ReassemblerConfig{})
+	pkt, e := reassembler.Accept(&frame)
+	assert.NoError(e)
+	require.NotNil(pkt)
+	require.NotNil(pkt.Interest)
+	nameEqual(assert, "/I", pkt.Interest)
+
+	_, e = ndn.NewFragmenter(20).Fragment(interest.ToPacket())
+	assert.Error(e)
+}
+
+func TestReassemblerLimits(t *testing.T) {
+	assert, require := makeAR(t)
+
+	fragmenter := ndn.NewFragmenter(600)
+	makeFrames := func() []*ndn.LpPacket {
+		data := ndn.MakeData("/D", make([]byte, 1000))
+		frames, e := fragmenter.Fragment(data.ToPacket())
+		require.NoError(e)
+		require.Len(frames, 2)
+		return frames
+	}
+
+	reassembler := ndn.NewReassembler(ndn.ReassemblerConfig{Timeout: 50 * time.Millisecond})
+	frames := makeFrames()
+	pkt, e := reassembler.Accept(frames[0])
+	assert.NoError(e)
+	assert.Nil(pkt)
+	time.Sleep(100 * time.Millisecond)
+	pkt, e = reassembler.Accept(frames[1]) // first fragment expired
+	assert.NoError(e)
+	assert.Nil(pkt)
+
+	reassembler = ndn.NewReassembler(ndn.ReassemblerConfig{MaxPartials: 1})
+	framesA, framesB := makeFrames(), makeFrames()
+	pkt, e = reassembler.Accept(framesA[0])
+	assert.NoError(e)
+	assert.Nil(pkt)
+	pkt, e = reassembler.Accept(framesB[0]) // evicts framesA
+	assert.NoError(e)
+	assert.Nil(pkt)
+	pkt, e = reassembler.Accept(framesB[1])
+	assert.NoError(e)
+	assert.NotNil(pkt)
+	pkt, e = reassembler.Accept(framesA[1])
+	assert.NoError(e)
+	assert.Nil(pkt)
+
+	reassembler = ndn.NewReassembler(ndn.ReassemblerConfig{MaxBytes: 100})
+	_, e = reassembler.Accept(makeFrames()[0])
+	assert.Error(e)
+}
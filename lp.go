@@ -16,15 +16,17 @@ func lpIsCritical(typ uint32) bool {
 // LpL3 contains layer 3 fields in NDNLPv2 header.
 type LpL3 struct {
 	PitToken        []byte
+	NackReason      uint8
 	NextHopFaceID   uint64
 	IncomingFaceID  uint64
 	CachePolicyType uint64 // CachePolicy wrapper is implicit
 	CongestionMark  uint64
+	SelfLearning    SelfLearningHeaders
 }
 
 // Empty returns true if LpL3 has zero fields.
 func (lph LpL3) Empty() bool {
-	return len(lph.PitToken) == 0 && lph.NextHopFaceID == 0 && lph.IncomingFaceID == 0 && lph.CachePolicyType == 0 && lph.CongestionMark == 0
+	return len(lph.PitToken) == 0 && lph.NackReason == an.NackNone && lph.NextHopFaceID == 0 && lph.IncomingFaceID == 0 && lph.CachePolicyType == 0 && lph.CongestionMark == 0 && lph.SelfLearning.Empty()
 }
 
 func (lph LpL3) encode() (fields []interface{}) {
@@ -32,6 +34,14 @@ func (lph LpL3) encode() (fields []interface{}) {
 		fields = append(fields, tlv.MakeElement(an.TtLpPitToken, lph.PitToken))
 	}
 
+	if lph.NackReason != an.NackNone {
+		var nackV []byte
+		if lph.NackReason != an.NackUnspecified {
+			nackV, _ = tlv.Encode(tlv.MakeElementNNI(an.TtLpNackReason, lph.NackReason))
+		}
+		fields = append(fields, tlv.MakeElement(an.TtLpNack, nackV))
+	}
+
 	if lph.NextHopFaceID != 0 {
 		fields = append(fields, tlv.MakeElementNNI(an.TtLpNextHopFaceID, lph.NextHopFaceID))
 	}
@@ -41,8 +51,8 @@ func (lph LpL3) encode() (fields []interface{}) {
 	}
 
 	if lph.CachePolicyType != 0 {
-		_, encodedCachePolicyType, _ := tlv.EncodeTlv(an.TtLpCachePolicyType, lph.CachePolicyType)
-		fields = append(fields, tlv.MakeElement(an.TtLpCachePolicy, encodedCachePolicyType))
+		cachePolicyV, _ := tlv.Encode(tlv.MakeElementNNI(an.TtLpCachePolicyType, lph.CachePolicyType))
+		fields = append(fields, tlv.MakeElement(an.TtLpCachePolicy, cachePolicyV))
 	}
 
 	if lph.CongestionMark != 0 {
@@ -52,7 +62,7 @@ func (lph LpL3) encode() (fields []interface{}) {
 	return fields
 }
 
-func (lph LpL3) inheritFrom(src LpL3) {
+func (lph *LpL3) inheritFrom(src LpL3) {
 	lph.PitToken = src.PitToken
 	lph.NextHopFaceID = src.NextHopFaceID
 	lph.IncomingFaceID = src.IncomingFaceID
@@ -60,32 +70,90 @@ func (lph LpL3) inheritFrom(src LpL3) {
 	lph.CongestionMark = src.CongestionMark
 }
 
+func (lph *LpL3) decodeNack(wire []byte) error {
+	lph.NackReason = an.NackUnspecified
+	d := tlv.Decoder(wire)
+	for _, field := range d.Elements() {
+		switch field.Type {
+		case an.TtLpNackReason:
+			if e := field.UnmarshalNNI(&lph.NackReason); e != nil {
+				return e
+			}
+		default:
+			if lpIsCritical(field.Type) {
+				return tlv.ErrCritical
+			}
+		}
+	}
+	return d.ErrUnlessEOF()
+}
+
+func (lph *LpL3) decodeCachePolicy(wire []byte) error {
+	d := tlv.Decoder(wire)
+	field, e := d.Element()
+	if e != nil {
+		return e
+	}
+	if field.Type != an.TtLpCachePolicyType {
+		return ErrUnexpectedElem
+	}
+	if e := field.UnmarshalNNI(&lph.CachePolicyType); e != nil {
+		return e
+	}
+	return d.ErrUnlessEOF()
+}
+
 // SelfLearningHeaders represents frame headers for self-learning.
 type SelfLearningHeaders struct {
-	NonDiscovery       bool
-	PrefixAnnouncement Data
+	// NonDiscovery indicates an Interest is not a discovery Interest.
+	NonDiscovery bool
+
+	// PrefixAnnouncement is a prefix announcement object attached to Data, created by MakePrefixAnnouncement.
+	// It is nil if absent.
+	PrefixAnnouncement *Data
 }
 
 // Empty returns true if SelfLearningHeaders has zero fields.
 func (slh SelfLearningHeaders) Empty() bool {
-	return slh.NonDiscovery == false && len(slh.PrefixAnnouncement.Name) == 0
+	return !slh.NonDiscovery && slh.PrefixAnnouncement == nil
 }
 
-// Encode encodes the self-learning headers
-func (slh SelfLearningHeaders) Encode() (fields []interface{}, err error) {
-	if slh.NonDiscovery == true {
+func (slh SelfLearningHeaders) encode() (fields []interface{}) {
+	if slh.NonDiscovery {
 		fields = append(fields, tlv.MakeElement(an.TtLpNonDiscovery, []byte{}))
 	}
 
-	if len(slh.PrefixAnnouncement.Name) != 0 {
-		announcementType, announcementValue, err := slh.PrefixAnnouncement.MarshalTlv()
-		if err != nil {
-			return nil, err
-		}
-		fields = append(fields, tlv.MakeElement(announcementType, announcementValue))
+	if slh.PrefixAnnouncement != nil {
+		fields = append(fields, lpPrefixAnnouncement{slh.PrefixAnnouncement})
 	}
 
-	return fields, nil
+	return fields
+}
+
+func (slh *SelfLearningHeaders) decodePrefixAnnouncement(wire []byte) error {
+	d := tlv.Decoder(wire)
+	field, e := d.Element()
+	if e != nil {
+		return e
+	}
+	if field.Type != an.TtData {
+		return ErrUnexpectedElem
+	}
+	var data Data
+	if e := data.UnmarshalBinary(field.Value); e != nil {
+		return e
+	}
+	slh.PrefixAnnouncement = &data
+	return d.ErrUnlessEOF()
+}
+
+// lpPrefixAnnouncement encodes the PrefixAnnouncement header, which wraps a Data packet.
+type lpPrefixAnnouncement struct {
+	data *Data
+}
+
+func (pa lpPrefixAnnouncement) MarshalTlv() (typ uint32, value []byte, e error) {
+	return tlv.EncodeTlv(an.TtLpPrefixAnnouncement, pa.data)
 }
 
 // PitTokenFromUint creates a PIT token from uint64, interpreted as big endian.
@@ -106,13 +174,16 @@ func PitTokenToUint(token []byte) uint64 {
 
 // LpPacket represents an NDNLPv2 frame.
 type LpPacket struct {
-	Sequence            util.Optional
-	FragIndex           int
-	FragCount           int
-	Acks                []uint64
-	TxSequence          util.Optional
-	SelfLearningHeaders SelfLearningHeaders
-	LpFragment          *Packet
+	Sequence   util.Optional
+	FragIndex  int
+	FragCount  int
+	Acks       []uint64
+	TxSequence util.Optional
+	LpFragment *Packet
+
+	// FragmentPayload is the TLV-VALUE of the LpFragment field when this frame carries one fragment of a larger packet.
+	// In this case, LpFragment contains only the LpL3 fields, which are present on the first fragment.
+	FragmentPayload []byte
 }
 
 // MakeLpPacket constructs an empty LpPacket.
@@ -123,6 +194,11 @@ func MakeLpPacket() LpPacket {
 		LpFragment: &Packet{}}
 }
 
+// IsFragment returns true if this frame carries one fragment of a larger packet.
+func (lp LpPacket) IsFragment() bool {
+	return lp.FragCount > 1
+}
+
 func (lp LpPacket) String() string {
 	retVal := ""
 	hasPrevValue := false
@@ -158,11 +234,21 @@ func (lp LpPacket) MarshalTlv() (typ uint32, value []byte, e error) {
 		return 0, nil, ErrFragment
 	}
 
-	if lp.TxSequence.HasValue != lp.Sequence.HasValue {
+	if lp.TxSequence.HasValue && !lp.Sequence.HasValue {
 		// Reliability requires a Sequence number
 		return 0, nil, ErrReliability
 	}
 
+	// Encode payload first, which determines NackReason
+	var encodedPayload []byte
+	if lp.FragmentPayload != nil {
+		encodedPayload = lp.FragmentPayload
+	} else if lp.LpFragment != nil && lp.LpFragment.hasL3() {
+		if encodedPayload, e = lp.LpFragment.encodeL3(); e != nil {
+			return 0, nil, e
+		}
+	}
+
 	// Actually perform encoding
 	fields := []interface{}{}
 
@@ -172,19 +258,19 @@ func (lp LpPacket) MarshalTlv() (typ uint32, value []byte, e error) {
 		fields = append(fields, tlv.MakeElement(an.TtLpSequence, seqNum))
 	}
 
-	// Add L3 headers (if any)
-	if lp.LpFragment.Interest != nil || lp.LpFragment.Data != nil {
-		for _, v := range lp.LpFragment.Lp.encode() {
-			fields = append(fields, v)
-		}
-	}
-
 	if lp.FragIndex >= 0 {
 		fields = append(fields, tlv.MakeElementNNI(an.TtLpFragIndex, lp.FragIndex))
 	}
 
 	if lp.FragCount > 0 {
-		fields = append(fields, tlv.MakeElementNNI(an.TtLpFragIndex, lp.FragIndex))
+		fields = append(fields, tlv.MakeElementNNI(an.TtLpFragCount, lp.FragCount))
+	}
+
+	// Add L3 headers (if any)
+	if lp.LpFragment != nil {
+		for _, v := range lp.LpFragment.Lp.encode() {
+			fields = append(fields, v)
+		}
 	}
 
 	for _, v := range lp.Acks {
@@ -199,20 +285,13 @@ func (lp LpPacket) MarshalTlv() (typ uint32, value []byte, e error) {
 		fields = append(fields, tlv.MakeElement(an.TtLpTxSequence, txSeqNum))
 	}
 
-	slHeaders, err := lp.SelfLearningHeaders.Encode()
-	if err != nil {
-		return 0, nil, err
-	}
-	for _, v := range slHeaders {
-		fields = append(fields, v)
+	// Self-learning headers have higher TLV-TYPE numbers than Ack and TxSequence
+	if lp.LpFragment != nil {
+		fields = append(fields, lp.LpFragment.Lp.SelfLearning.encode()...)
 	}
 
 	// Determine if not IDLE packet (has payload)
-	if lp.LpFragment.Data != nil || lp.LpFragment.Interest != nil {
-		encodedPayload, err := lp.LpFragment.encodeL3()
-		if err != nil {
-			return 0, nil, err
-		}
+	if encodedPayload != nil {
 		fields = append(fields, tlv.MakeElement(an.TtLpFragment, encodedPayload))
 	}
 
@@ -226,8 +305,8 @@ func (lp *LpPacket) UnmarshalTlv(typ uint32, value []byte) error {
 	lp.FragCount = -1
 	lp.Acks = []uint64{}
 	lp.TxSequence.Unset()
-	lp.SelfLearningHeaders = SelfLearningHeaders{}
 	lp.LpFragment = &Packet{}
+	lp.FragmentPayload = nil
 
 	if typ == an.TtInterest || typ == an.TtData {
 		// Decode bare Interest or Data
@@ -236,6 +315,7 @@ func (lp *LpPacket) UnmarshalTlv(typ uint32, value []byte) error {
 		return ErrNotLpFrame
 	}
 
+	var payload []byte
 	d := tlv.Decoder(value)
 	for _, field := range d.Elements() {
 		switch field.Type {
@@ -253,7 +333,11 @@ func (lp *LpPacket) UnmarshalTlv(typ uint32, value []byte) error {
 				return err
 			}
 		case an.TtLpPitToken:
-			copy(lp.LpFragment.Lp.PitToken, field.Value)
+			lp.LpFragment.Lp.PitToken = field.Value
+		case an.TtLpNack:
+			if err := lp.LpFragment.Lp.decodeNack(field.Value); err != nil {
+				return err
+			}
 		case an.TtLpNextHopFaceID:
 			if err := field.UnmarshalNNI(&lp.LpFragment.Lp.NextHopFaceID); err != nil {
 				return err
@@ -263,21 +347,19 @@ func (lp *LpPacket) UnmarshalTlv(typ uint32, value []byte) error {
 				return err
 			}
 		case an.TtLpCachePolicy:
-			decoderCachePolicy := tlv.Decoder(field.Value)
-			cachePolicyElement, err := decoderCachePolicy.Element()
-			if err != nil {
-				return err
-			}
-			if cachePolicyElement.Type != an.TtLpCachePolicyType {
-				return ErrUnexpectedElem
-			}
-			if err := cachePolicyElement.UnmarshalNNI(&lp.LpFragment.Lp.CachePolicyType); err != nil {
+			if err := lp.LpFragment.Lp.decodeCachePolicy(field.Value); err != nil {
 				return err
 			}
 		case an.TtLpCongestionMark:
 			if err := field.UnmarshalNNI(&lp.LpFragment.Lp.CongestionMark); err != nil {
 				return err
 			}
+		case an.TtLpNonDiscovery:
+			lp.LpFragment.Lp.SelfLearning.NonDiscovery = true
+		case an.TtLpPrefixAnnouncement:
+			if err := lp.LpFragment.Lp.SelfLearning.decodePrefixAnnouncement(field.Value); err != nil {
+				return err
+			}
 		case an.TtLpAck:
 			if len(field.Value) != 8 {
 				return ErrSequenceSize
@@ -289,19 +371,46 @@ func (lp *LpPacket) UnmarshalTlv(typ uint32, value []byte) error {
 			}
 			lp.TxSequence.Set(binary.BigEndian.Uint64(field.Value))
 		case an.TtLpFragment:
-			d1 := tlv.Decoder(field.Value)
-			field1, err := d1.Element()
-			if err != nil {
-				return err
-			}
-			err = lp.LpFragment.decodeL3(field1.Type, field1.Value)
-			if err != nil {
-				return err
-			}
-			if err := d1.ErrUnlessEOF(); err != nil {
-				return err
+			payload = field.Value
+		default:
+			if lpIsCritical(field.Type) {
+				return tlv.ErrCritical
 			}
 		}
 	}
-	return d.ErrUnlessEOF()
+	if err := d.ErrUnlessEOF(); err != nil {
+		return err
+	}
+
+	// FragIndex defaults to 0 and FragCount defaults to 1 if the other field is present
+	if lp.FragIndex >= 0 && lp.FragCount < 0 {
+		lp.FragCount = 1
+	} else if lp.FragCount >= 0 && lp.FragIndex < 0 {
+		lp.FragIndex = 0
+	}
+	if lp.FragIndex >= 0 && lp.FragIndex >= lp.FragCount {
+		return ErrFragment
+	}
+
+	if payload == nil {
+		// IDLE packet
+		return nil
+	}
+	if lp.IsFragment() {
+		if !lp.Sequence.HasValue {
+			return ErrFragment
+		}
+		lp.FragmentPayload = payload
+		return nil
+	}
+
+	d1 := tlv.Decoder(payload)
+	field1, err := d1.Element()
+	if err != nil {
+		return err
+	}
+	if err = lp.LpFragment.decodeL3(field1.Type, field1.Value); err != nil {
+		return err
+	}
+	return d1.ErrUnlessEOF()
 }
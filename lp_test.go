@@ -2,8 +2,10 @@ package ndn_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/eric135/go-ndn"
+	"github.com/eric135/go-ndn/an"
 	"github.com/eric135/go-ndn/tlv"
 )
 
@@ -36,6 +38,27 @@ func TestLpPacketDecode(t *testing.T) {
 	nameEqual(assert, "/A", interest)
 }
 
+func TestLpPacketDecodeUnknown(t *testing.T) {
+	assert, require := makeAR(t)
+
+	var pkt ndn.LpPacket
+	require.NoError(tlv.Decode(bytesFromHex("6413 unknown-ignored=FD03BC00 500D 050B 0703080141 0A0401020304"), &pkt))
+	assert.NotNil(pkt.LpFragment.Interest)
+
+	assert.Error(tlv.Decode(bytesFromHex("6413 unknown-critical=FD03C100 500D 050B 0703080141 0A0401020304"), &pkt))
+	assert.Error(tlv.Decode(bytesFromHex("6411 unknown-critical=6000 500D 050B 0703080141 0A0401020304"), &pkt))
+
+	var l3pkt ndn.Packet
+	require.NoError(tlv.Decode(bytesFromHex("6427 sequence=51088877665544332211 unknown-ignored=FD03BC00 "+
+		"nexthop=FD03300105 incoming=FD03310106 500D 050B 0703080141 0A0401020304"), &l3pkt))
+	assert.NotNil(l3pkt.Interest)
+	assert.EqualValues(5, l3pkt.Lp.NextHopFaceID)
+	assert.EqualValues(6, l3pkt.Lp.IncomingFaceID)
+
+	assert.Error(tlv.Decode(bytesFromHex("6413 unknown-critical=FD03C100 500D 050B 0703080141 0A0401020304"), &l3pkt))
+	assert.Error(tlv.Decode(bytesFromHex("6411 unknown-critical=6000 500D 050B 0703080141 0A0401020304"), &l3pkt))
+}
+
 func TestLpPacketDecodeBare(t *testing.T) {
 	assert, _ := makeAR(t)
 
@@ -46,3 +69,80 @@ func TestLpPacketDecodeBare(t *testing.T) {
 	interest := pkt.LpFragment.Interest
 	nameEqual(assert, "/A", interest)
 }
+
+func TestLpCachePolicy(t *testing.T) {
+	assert, require := makeAR(t)
+
+	var lph ndn.LpL3
+	lph.CachePolicyType = an.CachePolicyNoCache
+	data := ndn.MakeData("/A", lph)
+	wire, e := tlv.Encode(data.ToPacket())
+	require.NoError(e)
+	assert.Equal(bytesFromHex("FD033405 FD03350101"), wire[2:11])
+
+	var pkt ndn.Packet
+	require.NoError(tlv.Decode(wire, &pkt))
+	assert.EqualValues(an.CachePolicyNoCache, pkt.Lp.CachePolicyType)
+
+	var frame ndn.LpPacket
+	require.NoError(tlv.Decode(wire, &frame))
+	assert.EqualValues(an.CachePolicyNoCache, frame.LpFragment.Lp.CachePolicyType)
+}
+
+func TestLpSelfLearning(t *testing.T) {
+	assert, require := makeAR(t)
+
+	interest := ndn.MakeInterest("/A")
+	ipkt := interest.ToPacket()
+	ipkt.Lp.SelfLearning.NonDiscovery = true
+	wire, e := tlv.Encode(ipkt)
+	require.NoError(e)
+	assert.Equal(bytesFromHex("FD034C00"), wire[2:6])
+
+	var pkt ndn.Packet
+	require.NoError(tlv.Decode(wire, &pkt))
+	assert.True(pkt.Lp.SelfLearning.NonDiscovery)
+	assert.Nil(pkt.Lp.SelfLearning.PrefixAnnouncement)
+
+	pa, e := ndn.MakePrefixAnnouncement(ndn.ParseName("/A"), time.Hour, nil, ndn.DigestSigning)
+	require.NoError(e)
+	dpkt := &ndn.Packet{Data: &ndn.Data{Name: ndn.ParseName("/A/1")}}
+	dpkt.Lp.SelfLearning.PrefixAnnouncement = &pa
+	wire, e = tlv.Encode(dpkt)
+	require.NoError(e)
+
+	require.NoError(tlv.Decode(wire, &pkt))
+	assert.False(pkt.Lp.SelfLearning.NonDiscovery)
+	require.NotNil(pkt.Lp.SelfLearning.PrefixAnnouncement)
+	nameEqual(assert, pa.Name, pkt.Lp.SelfLearning.PrefixAnnouncement)
+	assert.NoError(ndn.DigestSigning.Verify(*pkt.Lp.SelfLearning.PrefixAnnouncement))
+	nameEqual(assert, "/A/1", pkt.Data)
+
+	var frame ndn.LpPacket
+	require.NoError(tlv.Decode(wire, &frame))
+	require.NotNil(frame.LpFragment.Lp.SelfLearning.PrefixAnnouncement)
+	parsed, e := ndn.ParsePrefixAnnouncement(*frame.LpFragment.Lp.SelfLearning.PrefixAnnouncement)
+	require.NoError(e)
+	nameEqual(assert, "/A", parsed.Prefix)
+	assert.Equal(time.Hour, parsed.ExpirationPeriod)
+
+	// header fields are in increasing TLV-TYPE order
+	frame = ndn.MakeLpPacket()
+	frame.Sequence.Set(uint64(1))
+	frame.Acks = []uint64{2}
+	frame.TxSequence.Set(uint64(3))
+	frame.LpFragment = dpkt
+	wire, e = tlv.Encode(frame)
+	require.NoError(e)
+	d := tlv.Decoder(wire)
+	outer, e := d.Element()
+	require.NoError(e)
+	d = tlv.Decoder(outer.Value)
+	var types []uint32
+	for _, field := range d.Elements() {
+		types = append(types, field.Type)
+	}
+	assert.Equal([]uint32{an.TtLpSequence, an.TtLpAck, an.TtLpTxSequence, an.TtLpPrefixAnnouncement, an.TtLpFragment}, types)
+
+	assert.Error(tlv.Decode(bytesFromHex("6408 FD035004 0700 0700"), &pkt))
+}
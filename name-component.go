@@ -62,6 +62,7 @@ func (comp *NameComponent) UnmarshalTlv(typ uint32, value []byte) error {
 }
 
 // String returns URI representation of this component.
+// Typed components following naming conventions use alternate URI representation, such as "seg=5".
 func (comp NameComponent) String() string {
 	var w strings.Builder
 	comp.writeStringTo(&w)
@@ -69,6 +70,10 @@ func (comp NameComponent) String() string {
 }
 
 func (comp NameComponent) writeStringTo(w *strings.Builder) {
+	if comp.writeAltURITo(w) {
+		return
+	}
+
 	w.WriteString(strconv.Itoa(int(comp.Type)))
 	w.WriteByte('=')
 
@@ -93,10 +98,14 @@ func (comp NameComponent) writeStringTo(w *strings.Builder) {
 
 // ParseNameComponent parses URI representation of name component.
 // It uses best effort and can accept any input.
+// Alternate URI representation of typed components, such as "seg=5", is recognized.
 func ParseNameComponent(input string) (comp NameComponent) {
 	comp.Type = uint32(an.TtGenericNameComponent)
 	pos := strings.IndexByte(input, '=')
 	if pos >= 0 {
+		if alt, ok := parseAltURI(input[:pos], input[pos+1:]); ok {
+			return alt
+		}
 		typ, e := strconv.ParseUint(input[:pos], 10, 32)
 		typ32 := uint32(typ)
 		if e == nil && isValidNameComponentType(typ32) {
@@ -2,8 +2,10 @@ package ndn_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/eric135/go-ndn"
+	"github.com/eric135/go-ndn/an"
 	"github.com/eric135/go-ndn/tlv"
 )
 
@@ -30,7 +32,17 @@ func TestNameComponent(t *testing.T) {
 		{input: "0804002081FF", t: 0x08, v: "002081FF", str: "8=%00%20%81%FF"},
 		{input: "0120(DC6D6840C6FAFB773D583CDBF465661C7B4B968E04ACD4D9015B1C4E53E59D6A)", t: 0x01,
 			v:   "DC6D6840C6FAFB773D583CDBF465661C7B4B968E04ACD4D9015B1C4E53E59D6A",
-			str: "1=%DCmh%40%C6%FA%FBw%3DX%3C%DB%F4ef%1C%7BK%96%8E%04%AC%D4%D9%01%5B%1CNS%E5%9Dj"},
+			str: "sha256digest=dc6d6840c6fafb773d583cdbf465661c7b4b968e04acd4d9015b1c4e53e59d6a"},
+		{input: "0220(DC6D6840C6FAFB773D583CDBF465661C7B4B968E04ACD4D9015B1C4E53E59D6A)", t: 0x02,
+			v:   "DC6D6840C6FAFB773D583CDBF465661C7B4B968E04ACD4D9015B1C4E53E59D6A",
+			str: "params-sha256=dc6d6840c6fafb773d583cdbf465661c7b4b968e04acd4d9015b1c4e53e59d6a"},
+		{input: "200141", t: 0x20, v: "41", str: "32=A"},
+		{input: "210105", t: 0x21, v: "05", str: "seg=5"},
+		{input: "22020100", t: 0x22, v: "0100", str: "off=256"},
+		{input: "230400010000", t: 0x23, v: "00010000", str: "v=65536"},
+		{input: "2408000000FFFFFFFFFF", t: 0x24, v: "000000FFFFFFFFFF", str: "t=1099511627775"},
+		{input: "250100", t: 0x25, v: "00", str: "seq=0"},
+		{input: "2103010203", t: 0x21, v: "010203", str: "33=%01%02%03"}, // not NonNegativeInteger
 	}
 
 	for _, tt := range tests {
@@ -53,3 +65,43 @@ func TestNameComponent(t *testing.T) {
 		}
 	}
 }
+
+func TestNameComponentConvention(t *testing.T) {
+	assert, _ := makeAR(t)
+
+	comp := ndn.MakeSegmentComponent(5)
+	assert.True(comp.IsSegment())
+	assert.False(comp.IsVersion())
+	n, e := comp.ToNumber()
+	assert.NoError(e)
+	assert.EqualValues(5, n)
+	assert.True(ndn.ParseNameComponent("seg=5").Equal(comp))
+	assert.True(ndn.ParseNameComponent("33=%05").Equal(comp))
+
+	assert.True(ndn.MakeByteOffsetComponent(1).IsByteOffset())
+	assert.True(ndn.MakeVersionComponent(1).IsVersion())
+	assert.True(ndn.MakeSequenceNumComponent(1).IsSequenceNum())
+	assert.True(ndn.MakeKeywordComponent([]byte("K")).IsKeyword())
+	assert.Equal("v=1", ndn.MakeVersionComponent(1).String())
+
+	timestamp := time.Date(2020, 11, 15, 1, 2, 3, 4000, time.UTC)
+	comp = ndn.MakeTimestampComponent(timestamp)
+	assert.True(comp.IsTimestamp())
+	t1, e := comp.ToTimestamp()
+	assert.NoError(e)
+	assert.True(timestamp.Equal(t1))
+
+	comp = ndn.MakeNameComponent(an.TtSegmentNameComponent, []byte{0x01, 0x02, 0x03})
+	assert.False(comp.IsSegment())
+	_, e = comp.ToNumber()
+	assert.Error(e)
+
+	// malformed alternate URI is treated as GenericNameComponent
+	assert.Equal("8=seg%3Dx", ndn.ParseNameComponent("seg=x").String())
+	assert.Equal("8=sha256digest%3D00", ndn.ParseNameComponent("sha256digest=00").String())
+
+	name := ndn.ParseName("/A/v=3/seg=0")
+	assert.Equal("/8=A/v=3/seg=0", name.String())
+	assert.True(name.Get(1).IsVersion())
+	assert.True(name.Get(2).IsSegment())
+}
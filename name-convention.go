@@ -0,0 +1,163 @@
+package ndn
+
+import (
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eric135/go-ndn/an"
+	"github.com/eric135/go-ndn/tlv"
+)
+
+// Typed name components follow NDN naming conventions, as described in NDN-TR-0022.
+
+// MakeKeywordComponent creates a keyword component.
+func MakeKeywordComponent(value []byte) NameComponent {
+	return MakeNameComponent(an.TtKeywordNameComponent, value)
+}
+
+// MakeSegmentComponent creates a segment number component.
+func MakeSegmentComponent(n uint64) NameComponent {
+	return makeNumberComponent(an.TtSegmentNameComponent, n)
+}
+
+// MakeByteOffsetComponent creates a byte offset component.
+func MakeByteOffsetComponent(n uint64) NameComponent {
+	return makeNumberComponent(an.TtByteOffsetNameComponent, n)
+}
+
+// MakeVersionComponent creates a version component.
+func MakeVersionComponent(n uint64) NameComponent {
+	return makeNumberComponent(an.TtVersionNameComponent, n)
+}
+
+// MakeTimestampComponent creates a timestamp component.
+// The value is the number of microseconds since Unix epoch.
+func MakeTimestampComponent(t time.Time) NameComponent {
+	return makeNumberComponent(an.TtTimestampNameComponent, uint64(t.UnixNano()/int64(time.Microsecond)))
+}
+
+// MakeSequenceNumComponent creates a sequence number component.
+func MakeSequenceNumComponent(n uint64) NameComponent {
+	return makeNumberComponent(an.TtSequenceNumNameComponent, n)
+}
+
+func makeNumberComponent(typ uint32, n uint64) NameComponent {
+	value, _ := tlv.NNI(n).MarshalBinary()
+	return MakeNameComponent(typ, value)
+}
+
+// IsKeyword determines whether this is a keyword component.
+func (comp NameComponent) IsKeyword() bool {
+	return comp.Type == an.TtKeywordNameComponent
+}
+
+// IsSegment determines whether this is a segment number component.
+func (comp NameComponent) IsSegment() bool {
+	return comp.isNumber(an.TtSegmentNameComponent)
+}
+
+// IsByteOffset determines whether this is a byte offset component.
+func (comp NameComponent) IsByteOffset() bool {
+	return comp.isNumber(an.TtByteOffsetNameComponent)
+}
+
+// IsVersion determines whether this is a version component.
+func (comp NameComponent) IsVersion() bool {
+	return comp.isNumber(an.TtVersionNameComponent)
+}
+
+// IsTimestamp determines whether this is a timestamp component.
+func (comp NameComponent) IsTimestamp() bool {
+	return comp.isNumber(an.TtTimestampNameComponent)
+}
+
+// IsSequenceNum determines whether this is a sequence number component.
+func (comp NameComponent) IsSequenceNum() bool {
+	return comp.isNumber(an.TtSequenceNumNameComponent)
+}
+
+func (comp NameComponent) isNumber(typ uint32) bool {
+	_, e := comp.ToNumber()
+	return comp.Type == typ && e == nil
+}
+
+// ToNumber interprets TLV-VALUE as NonNegativeInteger.
+// This does not check TLV-TYPE.
+func (comp NameComponent) ToNumber() (n uint64, e error) {
+	var nni tlv.NNI
+	if e := nni.UnmarshalBinary(comp.Value); e != nil {
+		return 0, e
+	}
+	return uint64(nni), nil
+}
+
+// ToTimestamp interprets TLV-VALUE as microseconds since Unix epoch.
+// This does not check TLV-TYPE.
+func (comp NameComponent) ToTimestamp() (t time.Time, e error) {
+	n, e := comp.ToNumber()
+	if e != nil {
+		return time.Time{}, e
+	}
+	return time.Unix(0, 0).Add(time.Duration(n) * time.Microsecond), nil
+}
+
+// Alternate URI representations of typed name components.
+var (
+	numberURIPrefixes = map[uint32]string{
+		an.TtSegmentNameComponent:     "seg",
+		an.TtByteOffsetNameComponent:  "off",
+		an.TtVersionNameComponent:     "v",
+		an.TtTimestampNameComponent:   "t",
+		an.TtSequenceNumNameComponent: "seq",
+	}
+	digestURIPrefixes = map[uint32]string{
+		an.TtImplicitSha256DigestComponent:   "sha256digest",
+		an.TtParametersSha256DigestComponent: "params-sha256",
+	}
+)
+
+// writeAltURITo writes alternate URI representation if applicable.
+func (comp NameComponent) writeAltURITo(w *strings.Builder) bool {
+	if prefix, ok := numberURIPrefixes[comp.Type]; ok {
+		if n, e := comp.ToNumber(); e == nil {
+			w.WriteString(prefix)
+			w.WriteByte('=')
+			w.WriteString(strconv.FormatUint(n, 10))
+			return true
+		}
+	}
+	if prefix, ok := digestURIPrefixes[comp.Type]; ok && comp.Length() == 32 {
+		w.WriteString(prefix)
+		w.WriteByte('=')
+		w.WriteString(hex.EncodeToString(comp.Value))
+		return true
+	}
+	return false
+}
+
+// parseAltURI parses alternate URI representation.
+func parseAltURI(prefix, value string) (comp NameComponent, ok bool) {
+	for typ, p := range numberURIPrefixes {
+		if p != prefix {
+			continue
+		}
+		n, e := strconv.ParseUint(value, 10, 64)
+		if e != nil {
+			return comp, false
+		}
+		return makeNumberComponent(typ, n), true
+	}
+	for typ, p := range digestURIPrefixes {
+		if p != prefix {
+			continue
+		}
+		digest, e := hex.DecodeString(value)
+		if e != nil || len(digest) != 32 {
+			return comp, false
+		}
+		return MakeNameComponent(typ, digest), true
+	}
+	return comp, false
+}
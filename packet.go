@@ -5,10 +5,13 @@
 //  Packet representation:
 //  - Interest
 //  - Data
+//  - Nack
 //  - Packet
 //
 //  Link protocol:
 //  - LpPacket
+//  - Fragmenter
+//  - Reassembler
 //
 //  Security abstraction:
 //  - Signer
@@ -35,6 +38,7 @@ type Packet struct {
 	l3digest []byte
 	Interest *Interest
 	Data     *Data
+	Nack     *Nack
 }
 
 func (pkt *Packet) String() string {
@@ -47,6 +51,8 @@ func (pkt *Packet) String() string {
 		return "I " + pkt.Interest.String() + suffix
 	case pkt.Data != nil:
 		return "D " + pkt.Data.String() + suffix
+	case pkt.Nack != nil:
+		return "N " + pkt.Nack.String() + suffix
 	}
 	return "(bad-NDN-packet)"
 }
@@ -62,7 +68,7 @@ func (pkt *Packet) MarshalTlv() (typ uint32, value []byte, e error) {
 	if e != nil {
 		return 0, nil, e
 	}
-	return tlv.EncodeTlv(an.TtLpPacket, tlv.MakeElement(an.TtLpFragment, payload))
+	return tlv.EncodeTlv(an.TtLpPacket, pkt.Lp.encode(), pkt.Lp.SelfLearning.encode(), tlv.MakeElement(an.TtLpFragment, payload))
 }
 
 // UnmarshalTlv decodes from wire format.
@@ -75,12 +81,36 @@ func (pkt *Packet) UnmarshalTlv(typ uint32, value []byte) error {
 	d := tlv.Decoder(value)
 	for _, field := range d.Elements() {
 		switch field.Type {
+		case an.TtLpSequence, an.TtLpFragIndex, an.TtLpFragCount:
+			// fragmentation fields are handled by LpPacket
 		case an.TtLpPitToken:
 			pkt.Lp.PitToken = field.Value
+		case an.TtLpNack:
+			if e := pkt.Lp.decodeNack(field.Value); e != nil {
+				return e
+			}
+		case an.TtLpNextHopFaceID:
+			if e := field.UnmarshalNNI(&pkt.Lp.NextHopFaceID); e != nil {
+				return e
+			}
+		case an.TtLpIncomingFaceID:
+			if e := field.UnmarshalNNI(&pkt.Lp.IncomingFaceID); e != nil {
+				return e
+			}
+		case an.TtLpCachePolicy:
+			if e := pkt.Lp.decodeCachePolicy(field.Value); e != nil {
+				return e
+			}
 		case an.TtLpCongestionMark:
 			if e := field.UnmarshalNNI(&pkt.Lp.CongestionMark); e != nil {
 				return e
 			}
+		case an.TtLpNonDiscovery:
+			pkt.Lp.SelfLearning.NonDiscovery = true
+		case an.TtLpPrefixAnnouncement:
+			if e := pkt.Lp.SelfLearning.decodePrefixAnnouncement(field.Value); e != nil {
+				return e
+			}
 		case an.TtLpFragment:
 			d1 := tlv.Decoder(field.Value)
 			field1, e := d1.Element()
@@ -94,6 +124,10 @@ func (pkt *Packet) UnmarshalTlv(typ uint32, value []byte) error {
 			if e := d1.ErrUnlessEOF(); e != nil {
 				return e
 			}
+		default:
+			if lpIsCritical(field.Type) {
+				return tlv.ErrCritical
+			}
 		}
 	}
 	return d.ErrUnlessEOF()
@@ -105,9 +139,15 @@ func (pkt *Packet) encodeL3() (payload []byte, e error) {
 	case pkt.Interest != nil:
 		pkt.l3type, pkt.l3value, e = pkt.Interest.MarshalTlv()
 		pkt.l3digest = nil
+		pkt.Lp.NackReason = an.NackNone
 	case pkt.Data != nil:
 		pkt.l3type, pkt.l3value, e = pkt.Data.MarshalTlv()
 		pkt.l3digest = nil
+		pkt.Lp.NackReason = an.NackNone
+	case pkt.Nack != nil:
+		pkt.l3type, pkt.l3value, e = pkt.Nack.Interest.MarshalTlv()
+		pkt.l3digest = nil
+		pkt.Lp.NackReason = pkt.Nack.Reason
 	}
 	if e != nil {
 		return nil, e
@@ -125,8 +165,16 @@ func (pkt *Packet) decodeL3(typ uint32, value []byte) error {
 		if e != nil {
 			return e
 		}
-		interest.packet = pkt
-		pkt.Interest = &interest
+		if pkt.Lp.NackReason != an.NackNone {
+			var nack Nack
+			nack.Reason = pkt.Lp.NackReason
+			nack.Interest = interest
+			nack.packet = pkt
+			pkt.Nack = &nack
+		} else {
+			interest.packet = pkt
+			pkt.Interest = &interest
+		}
 	case an.TtData:
 		var data Data
 		e := data.UnmarshalBinary(value)
@@ -142,3 +190,8 @@ func (pkt *Packet) decodeL3(typ uint32, value []byte) error {
 	pkt.l3type, pkt.l3value, pkt.l3digest = typ, value, nil
 	return nil
 }
+
+// hasL3 returns true if this packet contains an Interest, Data, or Nack.
+func (pkt *Packet) hasL3() bool {
+	return pkt.Interest != nil || pkt.Data != nil || pkt.Nack != nil
+}
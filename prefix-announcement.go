@@ -1,18 +1,140 @@
 package ndn
 
 import (
+	"time"
+
 	"github.com/eric135/go-ndn/an"
 	"github.com/eric135/go-ndn/tlv"
 )
 
-// PrefixAnnouncement holds the Content field of a prefix announcement object.
+// ComponentPrefixAnn is the keyword component that identifies a prefix announcement object.
+var ComponentPrefixAnn = MakeKeywordComponent([]byte("PA"))
+
+// PrefixAnnouncement represents a prefix announcement object.
+// Its Data packet has name /<prefix>/32=PA/<version>/<segment 0> and ContentType=PrefixAnn.
+// See https://redmine.named-data.net/projects/nfd/wiki/PrefixAnnouncement for format definition.
 type PrefixAnnouncement struct {
-	ExpirationPeriod int
-	ValidityPeriod   ValidityPeriod
+	// Prefix is the announced name prefix.
+	Prefix Name
+
+	// ExpirationPeriod is the lifetime of the announced route.
+	// It is encoded in milliseconds.
+	ExpirationPeriod time.Duration
+
+	// ValidityPeriod is an optional validity period of the announcement.
+	ValidityPeriod *ValidityPeriod
+}
+
+// MakePrefixAnnouncement creates and signs a prefix announcement object.
+// The returned Data can be carried in SelfLearningHeaders.PrefixAnnouncement.
+func MakePrefixAnnouncement(prefix Name, expiration time.Duration, validity *ValidityPeriod, signer Signer) (data Data, e error) {
+	pa := PrefixAnnouncement{
+		Prefix:           prefix,
+		ExpirationPeriod: expiration,
+		ValidityPeriod:   validity,
+	}
+	content, e := pa.MarshalBinary()
+	if e != nil {
+		return Data{}, e
+	}
+
+	version := MakeVersionComponent(uint64(time.Now().UnixNano() / int64(time.Microsecond)))
+	name := append(append(Name{}, prefix...), ComponentPrefixAnn, version, MakeSegmentComponent(0))
+	data = Data{
+		Name:        name,
+		ContentType: an.ContentPrefixAnn,
+		Content:     content,
+	}
+	if e = signer.Sign(&data); e != nil {
+		return Data{}, e
+	}
+	return data, nil
+}
+
+// ParsePrefixAnnouncement decodes a prefix announcement object.
+// This does not verify the signature.
+func ParsePrefixAnnouncement(data Data) (pa PrefixAnnouncement, e error) {
+	name := data.Name
+	if data.ContentType != an.ContentPrefixAnn || len(name) < 3 ||
+		!name.Get(-3).Equal(ComponentPrefixAnn) || !name.Get(-2).IsVersion() || !name.Get(-1).IsSegment() {
+		return pa, ErrPrefixAnn
+	}
+	if e = pa.UnmarshalBinary(data.Content); e != nil {
+		return PrefixAnnouncement{}, e
+	}
+	pa.Prefix = name.GetPrefix(-3)
+	return pa, nil
+}
+
+// EffectiveExpiration returns the remaining lifetime of the announced route at time now.
+// This is ExpirationPeriod, capped by the end of ValidityPeriod if present.
+// It returns zero if now is outside ValidityPeriod.
+func (pa PrefixAnnouncement) EffectiveExpiration(now time.Time) time.Duration {
+	expiration := pa.ExpirationPeriod
+	if pa.ValidityPeriod != nil {
+		if !pa.ValidityPeriod.Includes(now) {
+			return 0
+		}
+		if remaining := pa.ValidityPeriod.NotAfter.Sub(now); remaining < expiration {
+			expiration = remaining
+		}
+	}
+	if expiration < 0 {
+		return 0
+	}
+	return expiration
+}
+
+// MarshalBinary encodes the Content payload.
+// Prefix is not part of Content; it is encoded in the Data name.
+func (pa PrefixAnnouncement) MarshalBinary() (wire []byte, e error) {
+	if pa.ExpirationPeriod < 0 {
+		return nil, ErrPrefixAnn
+	}
+	fields := []interface{}{tlv.MakeElementNNI(an.MgmtExpirationPeriod, pa.ExpirationPeriod/time.Millisecond)}
+	if pa.ValidityPeriod != nil {
+		fields = append(fields, *pa.ValidityPeriod)
+	}
+	return tlv.Encode(fields...)
 }
 
-// MarshalTlv encodes this PrefixAnnouncment.
-func (a PrefixAnnouncement) MarshalTlv() (typ uint32, value []byte, e error) {
-	validityType, validityValue, _ := a.ValidityPeriod.MarshalTlv()
-	return tlv.EncodeTlv(an.TtContent, tlv.MakeElementNNI(an.MgmtExpirationPeriod, a.ExpirationPeriod), tlv.MakeElement(validityType, validityValue))
+// UnmarshalBinary decodes the Content payload.
+// ExpirationPeriod is required and ValidityPeriod is optional.
+func (pa *PrefixAnnouncement) UnmarshalBinary(wire []byte) error {
+	*pa = PrefixAnnouncement{}
+	hasExpiration := false
+	d := tlv.Decoder(wire)
+	for _, field := range d.Elements() {
+		switch field.Type {
+		case an.MgmtExpirationPeriod:
+			if hasExpiration {
+				return ErrPrefixAnn
+			}
+			if e := field.UnmarshalNNI(&pa.ExpirationPeriod); e != nil {
+				return e
+			}
+			pa.ExpirationPeriod *= time.Millisecond
+			hasExpiration = true
+		case an.TtValidityPeriod:
+			if pa.ValidityPeriod != nil {
+				return ErrPrefixAnn
+			}
+			var vp ValidityPeriod
+			if e := field.UnmarshalValue(&vp); e != nil {
+				return e
+			}
+			pa.ValidityPeriod = &vp
+		default:
+			if field.IsCriticalType() {
+				return tlv.ErrCritical
+			}
+		}
+	}
+	if e := d.ErrUnlessEOF(); e != nil {
+		return e
+	}
+	if !hasExpiration {
+		return ErrPrefixAnn
+	}
+	return nil
 }
@@ -0,0 +1,77 @@
+package ndn_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/eric135/go-ndn"
+	"github.com/eric135/go-ndn/an"
+	"github.com/eric135/go-ndn/tlv"
+)
+
+func TestPrefixAnnouncement(t *testing.T) {
+	assert, require := makeAR(t)
+
+	now := time.Now()
+	validity := ndn.MakeValidityPeriod(now.Add(-time.Hour), 2*time.Hour)
+	data, e := ndn.MakePrefixAnnouncement(ndn.ParseName("/A/B"), 30*time.Minute, &validity, ndn.DigestSigning)
+	require.NoError(e)
+	assert.EqualValues(an.ContentPrefixAnn, data.ContentType)
+	assert.Len(data.Name, 5)
+	assert.True(data.Name.Get(2).Equal(ndn.ComponentPrefixAnn))
+	assert.Equal("32=PA", data.Name.Get(2).String())
+	assert.True(data.Name.Get(3).IsVersion())
+	assert.Equal("seg=0", data.Name.Get(4).String())
+	assert.NoError(ndn.DigestSigning.Verify(data))
+
+	wire, e := tlv.Encode(data)
+	require.NoError(e)
+	var pkt ndn.Packet
+	require.NoError(tlv.Decode(wire, &pkt))
+
+	pa, e := ndn.ParsePrefixAnnouncement(*pkt.Data)
+	require.NoError(e)
+	nameEqual(assert, "/A/B", pa.Prefix)
+	assert.Equal(30*time.Minute, pa.ExpirationPeriod)
+	require.NotNil(pa.ValidityPeriod)
+	assert.Equal(validity, *pa.ValidityPeriod)
+
+	assert.Equal(30*time.Minute, pa.EffectiveExpiration(now))
+	assert.Equal(10*time.Minute, pa.EffectiveExpiration(validity.NotAfter.Add(-10*time.Minute)))
+	assert.Equal(time.Duration(0), pa.EffectiveExpiration(validity.NotAfter.Add(time.Second)))
+	assert.Equal(time.Duration(0), pa.EffectiveExpiration(validity.NotBefore.Add(-time.Second)))
+
+	data, e = ndn.MakePrefixAnnouncement(ndn.ParseName("/C"), time.Hour, nil, ndn.DigestSigning)
+	require.NoError(e)
+	pa, e = ndn.ParsePrefixAnnouncement(data)
+	require.NoError(e)
+	nameEqual(assert, "/C", pa.Prefix)
+	assert.Nil(pa.ValidityPeriod)
+	assert.Equal(time.Hour, pa.EffectiveExpiration(now))
+}
+
+func TestPrefixAnnouncementDecode(t *testing.T) {
+	assert, _ := makeAR(t)
+
+	name := ndn.ParseName("/A/32=PA/v=1/seg=0")
+	_, e := ndn.ParsePrefixAnnouncement(ndn.MakeData(name, ndn.ContentType(an.ContentPrefixAnn), bytesFromHex("6D0203E8")))
+	assert.NoError(e)
+
+	for _, tt := range []struct {
+		name    string
+		ct      uint
+		content string
+	}{
+		{"/A/32=PA/v=1/seg=0", an.ContentBlob, "6D0203E8"},               // wrong ContentType
+		{"/A/32=XX/v=1/seg=0", an.ContentPrefixAnn, "6D0203E8"},          // wrong keyword
+		{"/A/32=PA/seg=0", an.ContentPrefixAnn, "6D0203E8"},              // missing version
+		{"/A/32=PA/v=1/seg=0", an.ContentPrefixAnn, ""},                  // missing ExpirationPeriod
+		{"/A/32=PA/v=1/seg=0", an.ContentPrefixAnn, "6D0203E8 6D0203E8"}, // duplicate ExpirationPeriod
+		{"/A/32=PA/v=1/seg=0", an.ContentPrefixAnn, "6D0203E8 0100"},     // unknown critical element
+		{"/A/32=PA/v=1/seg=0", an.ContentPrefixAnn, "6D0203E8 FD00FD00"}, // bad ValidityPeriod
+	} {
+		data := ndn.MakeData(ndn.ParseName(tt.name), ndn.ContentType(tt.ct), bytesFromHex(tt.content))
+		_, e := ndn.ParsePrefixAnnouncement(data)
+		assert.Error(e, "%s %s", tt.name, tt.content)
+	}
+}
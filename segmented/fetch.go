@@ -0,0 +1,390 @@
+// Package segmented publishes and retrieves segmented objects.
+//
+// A segmented object is a sequence of Data packets, whose names are a common prefix followed by a segment number component.
+// Segment numbers start from zero.
+// The FinalBlockID field of each segment indicates the segment number of the last segment.
+package segmented
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/eric135/go-ndn"
+	"github.com/eric135/go-ndn/an"
+	"github.com/eric135/go-ndn/endpoint"
+	"github.com/eric135/go-ndn/l3"
+)
+
+// Error conditions.
+var (
+	ErrFinalBlock = errors.New("FinalBlockID is not a segment number")
+)
+
+// FetchOptions contains arguments to Fetch function.
+type FetchOptions struct {
+	// Fw specifies the L3 Forwarder.
+	// The default is the default Forwarder.
+	Fw l3.Forwarder
+
+	// Verifier specifies a Data verifier.
+	// The default is no verification.
+	Verifier ndn.Verifier
+
+	// Lifetime is the InterestLifetime of each Interest.
+	// The default is ndn.DefaultInterestLifetime.
+	Lifetime time.Duration
+
+	// RetxLimit is the maximum number of retransmissions of each segment.
+	// The default is DefaultRetxLimit.
+	RetxLimit int
+
+	// InitialCwnd is the initial congestion window, in segments.
+	// The default is DefaultInitialCwnd.
+	InitialCwnd int
+
+	// MaxCwnd is the maximum congestion window, in segments.
+	// It also limits the number of segments buffered for reordering.
+	// The default is DefaultMaxCwnd.
+	MaxCwnd int
+}
+
+func (opts *FetchOptions) applyDefaults() {
+	if opts.Lifetime <= 0 {
+		opts.Lifetime = ndn.DefaultInterestLifetime
+	}
+	if opts.RetxLimit <= 0 {
+		opts.RetxLimit = DefaultRetxLimit
+	}
+	if opts.InitialCwnd <= 0 {
+		opts.InitialCwnd = DefaultInitialCwnd
+	}
+	if opts.MaxCwnd <= 0 {
+		opts.MaxCwnd = DefaultMaxCwnd
+	}
+	if opts.MaxCwnd < opts.InitialCwnd {
+		opts.MaxCwnd = opts.InitialCwnd
+	}
+}
+
+// FetchOptions defaults.
+const (
+	DefaultRetxLimit   = 15
+	DefaultInitialCwnd = 2
+	DefaultMaxCwnd     = 256
+)
+
+// FetchStats contains segmented fetcher statistics.
+type FetchStats struct {
+	// NSegments is the total number of segments, or -1 if the final segment is unknown.
+	NSegments int64 `json:"nSegments"`
+
+	// NReceived is the number of segments received.
+	NReceived int64 `json:"nReceived"`
+
+	// NBytes is the total Content size of received segments.
+	NBytes int64 `json:"nBytes"`
+
+	// NRetx is the number of retransmitted Interests.
+	NRetx int64 `json:"nRetx"`
+
+	// NTimeouts is the number of Interests that have timed out.
+	NTimeouts int64 `json:"nTimeouts"`
+
+	// NCongMarks is the number of received segments carrying a congestion mark, or answered with Congestion Nacks.
+	NCongMarks int64 `json:"nCongMarks"`
+
+	// Cwnd is the current congestion window.
+	Cwnd float64 `json:"cwnd"`
+
+	// Elapsed is the duration since fetching started, until fetching ended.
+	Elapsed time.Duration `json:"elapsed"`
+}
+
+// Throughput returns average throughput in bytes per second.
+func (st FetchStats) Throughput() float64 {
+	if st.Elapsed <= 0 {
+		return 0
+	}
+	return float64(st.NBytes) / st.Elapsed.Seconds()
+}
+
+// Fetcher retrieves a segmented object.
+// Read() returns the object payload in order, and returns io.EOF after the last segment.
+// If a segment cannot be retrieved, Read() returns the preceding segments in order, and then returns the error.
+type Fetcher interface {
+	io.Reader
+
+	// Stats returns current statistics.
+	Stats() FetchStats
+}
+
+// Fetch starts retrieving a segmented object.
+// name is the name prefix of the segments, typically ending with a version component.
+// Fetching stops when ctx is canceled.
+//
+// Interests are pipelined within a congestion window, using additive-increase multiplicative-decrease.
+// The window is decreased upon timeout, congestion mark, or Congestion Nack, at most once per window of Interests.
+// The final segment number is discovered from the FinalBlockID of received segments;
+// until it is known, segments are requested one at a time.
+func Fetch(ctx context.Context, name ndn.Name, opts FetchOptions) Fetcher {
+	opts.applyDefaults()
+	pr, pw := io.Pipe()
+	f := &fetcher{
+		FetchOptions: opts,
+		name:         name,
+		pr:           pr,
+		pw:           pw,
+		results:      make(chan segmentResult),
+		out:          make(chan []byte),
+		buffer:       make(map[uint64][]byte),
+		nRetx:        make(map[uint64]int),
+		lastSeg:      math.MaxUint64,
+		cwnd:         float64(opts.InitialCwnd),
+		ssthresh:     float64(opts.MaxCwnd),
+		startTime:    time.Now(),
+	}
+	f.stats.NSegments = -1
+	f.stats.Cwnd = f.cwnd
+	go f.loop(ctx)
+	go f.writeLoop()
+	return f
+}
+
+type segmentResult struct {
+	seg  uint64
+	data *ndn.Data
+	e    error
+}
+
+type fetcher struct {
+	FetchOptions
+	name    ndn.Name
+	pr      *io.PipeReader
+	pw      *io.PipeWriter
+	results chan segmentResult
+	out     chan []byte
+	err     error // error passed to reader
+
+	// loop state
+	buffer        map[uint64][]byte // received segments, not yet delivered
+	pending       [][]byte          // in-order segments waiting to be written
+	nRetx         map[uint64]int    // retransmission count of outstanding segments
+	retxQueue     []uint64
+	nextSeg       uint64 // next new segment to request
+	nextDeliver   uint64 // next segment to move into pending
+	nOut          uint64 // number of segments written to out
+	lastSeg       uint64 // final segment number, MaxUint64 if unknown
+	nInflight     int
+	cwnd          float64
+	ssthresh      float64
+	recoveryPoint uint64 // window is not decreased again until a segment at or after this is answered
+	startTime     time.Time
+
+	statsMu sync.Mutex
+	stats   FetchStats
+}
+
+func (f *fetcher) Read(p []byte) (n int, e error) {
+	return f.pr.Read(p)
+}
+
+func (f *fetcher) Stats() FetchStats {
+	f.statsMu.Lock()
+	defer f.statsMu.Unlock()
+	st := f.stats
+	if st.Elapsed == 0 {
+		st.Elapsed = time.Since(f.startTime)
+	}
+	return st
+}
+
+func (f *fetcher) updateStats(fn func(st *FetchStats)) {
+	f.statsMu.Lock()
+	defer f.statsMu.Unlock()
+	fn(&f.stats)
+}
+
+func (f *fetcher) loop(parent context.Context) {
+	ctx, cancel := context.WithCancel(parent)
+	defer func() {
+		cancel()
+		for ; f.nInflight > 0; f.nInflight-- {
+			<-f.results
+		}
+		// deliver in-order segments received before the error
+	FLUSH:
+		for _, content := range f.pending {
+			select {
+			case f.out <- content:
+			case <-parent.Done():
+				break FLUSH
+			}
+		}
+		f.updateStats(func(st *FetchStats) { st.Elapsed = time.Since(f.startTime) })
+		close(f.out)
+	}()
+
+	for {
+		f.sendInterests(ctx)
+		if len(f.pending) == 0 && f.nextDeliver > f.lastSeg {
+			return
+		}
+
+		var outC chan<- []byte
+		var outV []byte
+		if len(f.pending) > 0 {
+			outC, outV = f.out, f.pending[0]
+		}
+
+		select {
+		case <-ctx.Done():
+			f.err = ctx.Err()
+			return
+		case outC <- outV:
+			f.pending = f.pending[1:]
+			f.nOut++
+		case r := <-f.results:
+			f.nInflight--
+			if e := f.handleResult(r); e != nil {
+				// segments before the failed segment are still delivered
+				f.err = e
+				if r.seg == 0 {
+					return
+				}
+				f.lastSeg = r.seg - 1
+			}
+		}
+	}
+}
+
+// sendInterests sends Interests permitted by the congestion window.
+func (f *fetcher) sendInterests(ctx context.Context) {
+	for f.nInflight < int(f.cwnd) {
+		var seg uint64
+		switch {
+		case len(f.retxQueue) > 0:
+			seg, f.retxQueue = f.retxQueue[0], f.retxQueue[1:]
+			if seg > f.lastSeg {
+				continue
+			}
+			f.updateStats(func(st *FetchStats) { st.NRetx++ })
+		case f.nextSeg > f.lastSeg:
+			return
+		case f.lastSeg == math.MaxUint64 && f.nInflight > 0: // final segment unknown
+			return
+		case f.nextSeg-f.nOut >= uint64(f.MaxCwnd): // too many segments buffered
+			return
+		default:
+			seg = f.nextSeg
+			f.nextSeg++
+		}
+
+		f.nInflight++
+		go f.fetchSegment(ctx, seg)
+	}
+}
+
+func (f *fetcher) fetchSegment(ctx context.Context, seg uint64) {
+	interest := ndn.MakeInterest(append(append(ndn.Name{}, f.name...), ndn.MakeSegmentComponent(seg)), f.Lifetime)
+	data, e := endpoint.Consume(ctx, interest, endpoint.ConsumerOptions{
+		Fw:       f.Fw,
+		Verifier: f.Verifier,
+	})
+	f.results <- segmentResult{seg, data, e}
+}
+
+func (f *fetcher) handleResult(r segmentResult) error {
+	if r.seg > f.lastSeg {
+		return nil
+	}
+
+	var nackErr endpoint.NackError
+	switch {
+	case r.e == nil:
+	case errors.Is(r.e, endpoint.ErrExpire):
+		f.updateStats(func(st *FetchStats) { st.NTimeouts++ })
+		f.decreaseCwnd(r.seg)
+		return f.retransmit(r.seg, r.e)
+	case errors.As(r.e, &nackErr) && nackErr.Reason == an.NackCongestion:
+		f.updateStats(func(st *FetchStats) { st.NCongMarks++ })
+		f.decreaseCwnd(r.seg)
+		return f.retransmit(r.seg, r.e)
+	default:
+		return fmt.Errorf("segment %d: %w", r.seg, r.e)
+	}
+
+	delete(f.nRetx, r.seg)
+	if r.data.FinalBlock.Valid() && f.err == nil {
+		if !r.data.FinalBlock.IsSegment() {
+			return ErrFinalBlock
+		}
+		lastSeg, _ := r.data.FinalBlock.ToNumber()
+		f.lastSeg = lastSeg
+		f.updateStats(func(st *FetchStats) { st.NSegments = int64(lastSeg) + 1 })
+	}
+
+	if r.seg <= f.lastSeg && r.seg >= f.nextDeliver {
+		f.buffer[r.seg] = r.data.Content
+		f.updateStats(func(st *FetchStats) {
+			st.NReceived++
+			st.NBytes += int64(len(r.data.Content))
+		})
+	}
+	for content, ok := f.buffer[f.nextDeliver]; ok; content, ok = f.buffer[f.nextDeliver] {
+		delete(f.buffer, f.nextDeliver)
+		f.pending = append(f.pending, content)
+		f.nextDeliver++
+	}
+
+	if r.data.ToPacket().Lp.CongestionMark != 0 {
+		f.updateStats(func(st *FetchStats) { st.NCongMarks++ })
+		f.decreaseCwnd(r.seg)
+	} else {
+		f.increaseCwnd()
+	}
+	return nil
+}
+
+func (f *fetcher) retransmit(seg uint64, cause error) error {
+	if f.nRetx[seg]++; f.nRetx[seg] > f.RetxLimit {
+		return fmt.Errorf("segment %d: %w", seg, cause)
+	}
+	f.retxQueue = append(f.retxQueue, seg)
+	return nil
+}
+
+func (f *fetcher) increaseCwnd() {
+	if f.cwnd < f.ssthresh {
+		f.cwnd++
+	} else {
+		f.cwnd += 1 / f.cwnd
+	}
+	f.cwnd = math.Min(f.cwnd, float64(f.MaxCwnd))
+	f.updateStats(func(st *FetchStats) { st.Cwnd = f.cwnd })
+}
+
+func (f *fetcher) decreaseCwnd(seg uint64) {
+	if seg < f.recoveryPoint {
+		return
+	}
+	f.recoveryPoint = f.nextSeg
+	f.cwnd = math.Max(f.cwnd/2, 1)
+	f.ssthresh = f.cwnd
+	f.updateStats(func(st *FetchStats) { st.Cwnd = f.cwnd })
+}
+
+func (f *fetcher) writeLoop() {
+	for content := range f.out {
+		if _, e := f.pw.Write(content); e != nil {
+			for range f.out {
+			}
+			return
+		}
+	}
+	f.pw.CloseWithError(f.err)
+}
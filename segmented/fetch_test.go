@@ -0,0 +1,149 @@
+package segmented_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/eric135/go-ndn"
+	"github.com/eric135/go-ndn/an"
+	"github.com/eric135/go-ndn/endpoint"
+	"github.com/eric135/go-ndn/l3"
+	"github.com/eric135/go-ndn/segmented"
+)
+
+func TestFetch(t *testing.T) {
+	assert, require := makeAR(t)
+	fw := l3.NewForwarder()
+	prefix := ndn.ParseName("/A/v")
+	content := makeContent(100*1000 + 500)
+
+	p, e := endpoint.Produce(context.Background(), endpoint.ProducerOptions{
+		Prefix:  prefix,
+		Handler: segmentHandler(prefix, content, 1000, nil),
+		Fw:      fw,
+	})
+	require.NoError(e)
+	defer p.Close()
+
+	f := segmented.Fetch(context.Background(), prefix, segmented.FetchOptions{Fw: fw})
+	received, e := ioutil.ReadAll(f)
+	assert.NoError(e)
+	assert.Equal(content, received)
+
+	st := f.Stats()
+	assert.EqualValues(101, st.NSegments)
+	assert.EqualValues(101, st.NReceived)
+	assert.EqualValues(len(content), st.NBytes)
+	assert.Zero(st.NRetx)
+	assert.Greater(st.Cwnd, float64(segmented.DefaultInitialCwnd))
+	assert.Greater(st.Throughput(), 0.0)
+}
+
+func TestFetchLoss(t *testing.T) {
+	assert, _ := makeAR(t)
+	fw := l3.NewForwarder()
+	prefix := ndn.ParseName("/A/v")
+	content := makeContent(200 * 100)
+
+	defer startTestProducer(fw, l3.MemoryTransportConfig{
+		LossRate:    0.1,
+		ReorderRate: 0.1,
+		Seed:        1,
+	}, serveData(segmentHandler(prefix, content, 100, nil)))()
+
+	f := segmented.Fetch(context.Background(), prefix, segmented.FetchOptions{
+		Fw:       fw,
+		Lifetime: 100 * time.Millisecond,
+	})
+	received, e := ioutil.ReadAll(f)
+	assert.NoError(e)
+	assert.Equal(content, received)
+
+	st := f.Stats()
+	assert.EqualValues(200, st.NReceived)
+	assert.NotZero(st.NTimeouts)
+	assert.Equal(st.NTimeouts, st.NRetx)
+}
+
+func TestFetchCongestion(t *testing.T) {
+	assert, _ := makeAR(t)
+	fw := l3.NewForwarder()
+	prefix := ndn.ParseName("/A/v")
+	content := makeContent(300 * 100)
+
+	makeData := segmentHandler(prefix, content, 100, nil)
+	var nackOnce sync.Once
+	defer startTestProducer(fw, l3.MemoryTransportConfig{}, func(interest ndn.Interest) *ndn.Packet {
+		data, _ := makeData(context.Background(), interest)
+		seg, _ := interest.Name.Get(-1).ToNumber()
+		switch {
+		case seg == 40:
+			var reply *ndn.Packet
+			nackOnce.Do(func() { reply = ndn.MakeNack(interest, an.NackCongestion).ToPacket() })
+			if reply != nil {
+				return reply
+			}
+		case seg%50 == 20:
+			reply := &ndn.Packet{Data: &data}
+			reply.Lp.CongestionMark = 1
+			return reply
+		}
+		return &ndn.Packet{Data: &data}
+	})()
+
+	f := segmented.Fetch(context.Background(), prefix, segmented.FetchOptions{Fw: fw, MaxCwnd: 32})
+	received, e := ioutil.ReadAll(f)
+	assert.NoError(e)
+	assert.Equal(content, received)
+
+	st := f.Stats()
+	assert.GreaterOrEqual(st.NCongMarks, int64(7))
+	assert.EqualValues(1, st.NRetx)
+	assert.LessOrEqual(st.Cwnd, 32.0)
+}
+
+func TestFetchError(t *testing.T) {
+	assert, require := makeAR(t)
+	fw := l3.NewForwarder()
+	prefix := ndn.ParseName("/A/v")
+	content := makeContent(20 * 100)
+
+	p, e := endpoint.Produce(context.Background(), endpoint.ProducerOptions{
+		Prefix: prefix,
+		Handler: segmentHandler(prefix, content, 100, func(seg uint64, data *ndn.Data) error {
+			if seg == 12 {
+				return endpoint.ReplyNack(an.NackNoRoute)
+			}
+			return nil
+		}),
+		Fw: fw,
+	})
+	require.NoError(e)
+	defer p.Close()
+
+	f := segmented.Fetch(context.Background(), prefix, segmented.FetchOptions{Fw: fw})
+	received, e := ioutil.ReadAll(f)
+	var nackErr endpoint.NackError
+	if assert.True(errors.As(e, &nackErr)) {
+		assert.EqualValues(an.NackNoRoute, nackErr.Reason)
+	}
+	assert.Equal(content[:1200], received)
+}
+
+func TestFetchCancel(t *testing.T) {
+	assert, _ := makeAR(t)
+	fw := l3.NewForwarder()
+	defer startTestProducer(fw, l3.MemoryTransportConfig{}, func(interest ndn.Interest) *ndn.Packet {
+		return nil
+	})()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	f := segmented.Fetch(ctx, ndn.ParseName("/A/v"), segmented.FetchOptions{Fw: fw})
+	_, e := ioutil.ReadAll(f)
+	assert.Equal(context.DeadlineExceeded, e)
+}
@@ -0,0 +1,167 @@
+package segmented
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"time"
+
+	"github.com/eric135/go-ndn"
+	"github.com/eric135/go-ndn/endpoint"
+	"github.com/eric135/go-ndn/l3"
+)
+
+// PublishOptions contains arguments to Publish function.
+type PublishOptions struct {
+	// Fw specifies the L3 Forwarder.
+	// The default is the default Forwarder.
+	Fw l3.Forwarder
+
+	// NoAdvertise disables prefix announcement.
+	// The default is announcing the prefix.
+	NoAdvertise bool
+
+	// SegmentSize is the maximum Content size of each segment.
+	// The default is DefaultSegmentSize.
+	SegmentSize int
+
+	// Version is the version number appended to the prefix.
+	// The default is current Unix timestamp in microseconds.
+	Version uint64
+
+	// Freshness is the FreshnessPeriod of each segment.
+	// The default is zero.
+	Freshness time.Duration
+
+	// Signer signs each segment.
+	// The default is keeping the Null signature.
+	Signer ndn.Signer
+}
+
+func (opts *PublishOptions) applyDefaults() {
+	if opts.SegmentSize <= 0 {
+		opts.SegmentSize = DefaultSegmentSize
+	}
+	if opts.Version == 0 {
+		opts.Version = uint64(time.Now().UnixNano() / int64(time.Microsecond))
+	}
+}
+
+// PublishOptions defaults.
+const (
+	DefaultSegmentSize = 4096
+)
+
+// Publisher serves a segmented object from memory.
+type Publisher interface {
+	io.Closer
+
+	// Name returns the versioned name of the object, i.e. the name prefix of the segments.
+	Name() ndn.Name
+
+	// NSegments returns the number of segments.
+	NSegments() int
+}
+
+// Publish starts serving a segmented object.
+// content is the object payload; it may be []byte or io.Reader, which is read entirely before this function returns.
+// prefix is the object name without version; the segment names are prefix, version component, and segment number component.
+// An Interest for the prefix or the versioned name with CanBePrefix retrieves the first segment, which reveals the version.
+// The publisher stops when ctx is canceled or Close is called.
+func Publish(ctx context.Context, prefix ndn.Name, content interface{}, opts PublishOptions) (Publisher, error) {
+	opts.applyDefaults()
+
+	var payload []byte
+	switch c := content.(type) {
+	case []byte:
+		payload = c
+	case io.Reader:
+		var buf bytes.Buffer
+		if _, e := buf.ReadFrom(c); e != nil {
+			return nil, e
+		}
+		payload = buf.Bytes()
+	default:
+		panic("content must be []byte or io.Reader")
+	}
+
+	p := &publisher{
+		name: append(append(ndn.Name{}, prefix...), ndn.MakeVersionComponent(opts.Version)),
+	}
+	if e := p.makeSegments(payload, opts); e != nil {
+		return nil, e
+	}
+
+	var e error
+	p.producer, e = endpoint.Produce(ctx, endpoint.ProducerOptions{
+		Prefix:      prefix,
+		NoAdvertise: opts.NoAdvertise,
+		Handler:     p.handleInterest,
+		Fw:          opts.Fw,
+	})
+	if e != nil {
+		return nil, e
+	}
+	return p, nil
+}
+
+type publisher struct {
+	name     ndn.Name
+	segments []ndn.Data
+	producer endpoint.Producer
+}
+
+func (p *publisher) Name() ndn.Name {
+	return p.name
+}
+
+func (p *publisher) NSegments() int {
+	return len(p.segments)
+}
+
+func (p *publisher) Close() error {
+	return p.producer.Close()
+}
+
+// makeSegments chunks and signs the payload.
+// An empty payload is published as one segment with empty Content.
+func (p *publisher) makeSegments(payload []byte, opts PublishOptions) error {
+	nSegments := (len(payload) + opts.SegmentSize - 1) / opts.SegmentSize
+	if nSegments == 0 {
+		nSegments = 1
+	}
+	finalBlock := ndn.MakeSegmentComponent(uint64(nSegments - 1))
+
+	p.segments = make([]ndn.Data, nSegments)
+	for i := range p.segments {
+		chunk := payload[i*opts.SegmentSize:]
+		if len(chunk) > opts.SegmentSize {
+			chunk = chunk[:opts.SegmentSize]
+		}
+
+		name := append(append(ndn.Name{}, p.name...), ndn.MakeSegmentComponent(uint64(i)))
+		data := ndn.MakeData(name, chunk, opts.Freshness, ndn.FinalBlock(finalBlock))
+		if opts.Signer != nil {
+			if e := opts.Signer.Sign(&data); e != nil {
+				return e
+			}
+		}
+		p.segments[i] = data
+	}
+	return nil
+}
+
+func (p *publisher) handleInterest(ctx context.Context, interest ndn.Interest) (ndn.Data, error) {
+	switch {
+	case len(interest.Name) == len(p.name)+1 && p.name.IsPrefixOf(interest.Name):
+		comp := interest.Name.Get(-1)
+		seg, _ := comp.ToNumber()
+		if !comp.IsSegment() || seg >= uint64(len(p.segments)) {
+			return ndn.Data{}, nil
+		}
+		return p.segments[seg], nil
+	case interest.CanBePrefix && interest.Name.IsPrefixOf(p.name):
+		return p.segments[0], nil
+	}
+	return ndn.Data{}, nil
+}
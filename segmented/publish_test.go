@@ -0,0 +1,87 @@
+package segmented_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/eric135/go-ndn"
+	"github.com/eric135/go-ndn/endpoint"
+	"github.com/eric135/go-ndn/l3"
+	"github.com/eric135/go-ndn/segmented"
+)
+
+func TestPublishFetch(t *testing.T) {
+	assert, require := makeAR(t)
+	fw := l3.NewForwarder()
+	content := makeContent(10500)
+
+	p, e := segmented.Publish(context.Background(), ndn.ParseName("/A"), content, segmented.PublishOptions{
+		Fw:          fw,
+		SegmentSize: 1000,
+		Version:     7,
+		Signer:      ndn.DigestSigning,
+	})
+	require.NoError(e)
+	defer p.Close()
+	assert.Equal(11, p.NSegments())
+	assert.Equal("/8=A/v=7", p.Name().String())
+	assert.True(p.Name().Get(-1).IsVersion())
+
+	f := segmented.Fetch(context.Background(), p.Name(), segmented.FetchOptions{
+		Fw:       fw,
+		Verifier: ndn.DigestSigning,
+	})
+	received, e := ioutil.ReadAll(f)
+	assert.NoError(e)
+	assert.Equal(content, received)
+	assert.EqualValues(11, f.Stats().NSegments)
+}
+
+func TestPublishReader(t *testing.T) {
+	assert, require := makeAR(t)
+	fw := l3.NewForwarder()
+	content := makeContent(2500)
+
+	p, e := segmented.Publish(context.Background(), ndn.ParseName("/A"), bytes.NewReader(content), segmented.PublishOptions{
+		Fw:          fw,
+		SegmentSize: 1000,
+	})
+	require.NoError(e)
+	defer p.Close()
+	assert.Equal(3, p.NSegments())
+	assert.True(p.Name().Get(-1).IsVersion())
+
+	// discover version with CanBePrefix
+	data, e := endpoint.Consume(context.Background(), ndn.MakeInterest("/A", ndn.CanBePrefixFlag),
+		endpoint.ConsumerOptions{Fw: fw})
+	require.NoError(e)
+	assert.True(p.Name().IsPrefixOf(data.Name))
+	assert.Equal(len(p.Name())+1, len(data.Name))
+	assert.True(data.Name.Get(-1).Equal(ndn.MakeSegmentComponent(0)))
+	assert.True(data.FinalBlock.Equal(ndn.MakeSegmentComponent(2)))
+	assert.Equal(content[:1000], data.Content)
+
+	// nonexistent segment
+	_, e = endpoint.Consume(context.Background(),
+		ndn.MakeInterest(append(append(ndn.Name{}, p.Name()...), ndn.MakeSegmentComponent(3)), 100*time.Millisecond),
+		endpoint.ConsumerOptions{Fw: fw})
+	assert.Equal(endpoint.ErrExpire, e)
+}
+
+func TestPublishEmpty(t *testing.T) {
+	assert, require := makeAR(t)
+	fw := l3.NewForwarder()
+
+	p, e := segmented.Publish(context.Background(), ndn.ParseName("/A"), []byte{}, segmented.PublishOptions{Fw: fw})
+	require.NoError(e)
+	defer p.Close()
+	assert.Equal(1, p.NSegments())
+
+	f := segmented.Fetch(context.Background(), p.Name(), segmented.FetchOptions{Fw: fw})
+	received, e := ioutil.ReadAll(f)
+	assert.NoError(e)
+	assert.Len(received, 0)
+}
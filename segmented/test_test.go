@@ -0,0 +1,82 @@
+package segmented_test
+
+import (
+	"context"
+	"math/rand"
+
+	"github.com/eric135/go-ndn"
+	"github.com/eric135/go-ndn/l3"
+	"github.com/usnistgov/ndn-dpdk/core/testenv"
+)
+
+var makeAR = testenv.MakeAR
+
+// makeContent creates random payload.
+func makeContent(size int) []byte {
+	content := make([]byte, size)
+	rand.Read(content)
+	return content
+}
+
+// segmentHandler returns a function that creates segment Data of content under prefix.
+// modify, if not nil, may alter the Data or return an error for a segment.
+func segmentHandler(prefix ndn.Name, content []byte, segmentSize int,
+	modify func(seg uint64, data *ndn.Data) error) func(ctx context.Context, interest ndn.Interest) (ndn.Data, error) {
+	nSegments := (len(content) + segmentSize - 1) / segmentSize
+	return func(ctx context.Context, interest ndn.Interest) (ndn.Data, error) {
+		comp := interest.Name.Get(len(prefix))
+		seg, _ := comp.ToNumber()
+		if !comp.IsSegment() || int(seg) >= nSegments {
+			return ndn.Data{}, nil
+		}
+
+		start := int(seg) * segmentSize
+		end := start + segmentSize
+		if end > len(content) {
+			end = len(content)
+		}
+		data := ndn.MakeData(interest, content[start:end],
+			ndn.FinalBlock(ndn.MakeSegmentComponent(uint64(nSegments-1))))
+		if modify != nil {
+			if e := modify(uint64(seg), &data); e != nil {
+				return ndn.Data{}, e
+			}
+		}
+		return data, nil
+	}
+}
+
+// startTestProducer adds a face to the forwarder over a simulated link with "/" route.
+// handler is invoked for each incoming Interest; if it returns a packet, the packet is sent on the face.
+func startTestProducer(fw l3.Forwarder, cfg l3.MemoryTransportConfig, handler func(interest ndn.Interest) *ndn.Packet) (close func()) {
+	trA, trB := l3.NewMemoryTransportPair(cfg)
+	ff, e := fw.AddTransport(trA)
+	if e != nil {
+		panic(e)
+	}
+	ff.AddRoute(ndn.ParseName("/"), 0)
+	face, _ := l3.NewFace(trB)
+	go func() {
+		for pkt := range face.Rx() {
+			if pkt.Interest == nil {
+				continue
+			}
+			if reply := handler(*pkt.Interest); reply != nil {
+				reply.Lp.PitToken = pkt.Lp.PitToken
+				face.Tx() <- reply
+			}
+		}
+	}()
+	return func() { ff.Close() }
+}
+
+// serveData adapts a segmentHandler for startTestProducer.
+func serveData(handler func(ctx context.Context, interest ndn.Interest) (ndn.Data, error)) func(interest ndn.Interest) *ndn.Packet {
+	return func(interest ndn.Interest) *ndn.Packet {
+		data, e := handler(context.Background(), interest)
+		if e != nil || !data.CanSatisfy(interest) {
+			return nil
+		}
+		return &ndn.Packet{Data: &data}
+	}
+}
@@ -0,0 +1,89 @@
+package util
+
+import (
+	"time"
+)
+
+// RttEstimator defaults.
+const (
+	DefaultInitialRto = 1 * time.Second
+	DefaultMinRto     = 200 * time.Millisecond
+	DefaultMaxRto     = 60 * time.Second
+)
+
+// RttEstimator estimates round-trip time and computes retransmission timeout, as described in RFC 6298.
+// The zero value is ready to use with default parameters.
+type RttEstimator struct {
+	// InitialRto is the RTO before any RTT sample is collected.
+	// The default is DefaultInitialRto.
+	InitialRto time.Duration
+
+	// MinRto is the lower bound of RTO.
+	// The default is DefaultMinRto.
+	MinRto time.Duration
+
+	// MaxRto is the upper bound of RTO.
+	// The default is DefaultMaxRto.
+	MaxRto time.Duration
+
+	srtt      time.Duration
+	rttvar    time.Duration
+	rto       time.Duration
+	hasSample bool
+}
+
+// AddMeasurement updates the estimator with an RTT sample.
+// Samples should not be collected from retransmitted packets, per Karn's algorithm.
+func (rtte *RttEstimator) AddMeasurement(rtt time.Duration) {
+	if !rtte.hasSample {
+		rtte.srtt = rtt
+		rtte.rttvar = rtt / 2
+		rtte.hasSample = true
+	} else {
+		diff := rtte.srtt - rtt
+		if diff < 0 {
+			diff = -diff
+		}
+		rtte.rttvar = (3*rtte.rttvar + diff) / 4
+		rtte.srtt = (7*rtte.srtt + rtt) / 8
+	}
+	rtte.setRto(rtte.srtt + 4*rtte.rttvar)
+}
+
+// Backoff doubles the RTO, after a retransmission timer has expired.
+func (rtte *RttEstimator) Backoff() {
+	rtte.setRto(2 * rtte.Rto())
+}
+
+// Rto returns the current retransmission timeout.
+func (rtte *RttEstimator) Rto() time.Duration {
+	if rtte.rto == 0 {
+		if rtte.InitialRto > 0 {
+			return rtte.InitialRto
+		}
+		return DefaultInitialRto
+	}
+	return rtte.rto
+}
+
+// Srtt returns the smoothed RTT, or zero if no sample has been collected.
+func (rtte *RttEstimator) Srtt() time.Duration {
+	return rtte.srtt
+}
+
+func (rtte *RttEstimator) setRto(rto time.Duration) {
+	minRto, maxRto := rtte.MinRto, rtte.MaxRto
+	if minRto <= 0 {
+		minRto = DefaultMinRto
+	}
+	if maxRto <= 0 {
+		maxRto = DefaultMaxRto
+	}
+	switch {
+	case rto < minRto:
+		rto = minRto
+	case rto > maxRto:
+		rto = maxRto
+	}
+	rtte.rto = rto
+}
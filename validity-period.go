@@ -1,17 +1,151 @@
 package ndn
 
 import (
+	"time"
+
 	"github.com/eric135/go-ndn/an"
 	"github.com/eric135/go-ndn/tlv"
 )
 
+const validityPeriodFormat = "20060102T150405"
+
 // ValidityPeriod contains two timestamps indicating a temporal range of validity.
+// Both ends are inclusive.
+// On the wire, timestamps are in UTC with second precision, in ISO 8601 compact format YYYYMMDDThhmmss.
 type ValidityPeriod struct {
-	NotBefore []byte
-	NotAfter  []byte
+	NotBefore time.Time
+	NotAfter  time.Time
+}
+
+// MakeValidityPeriod creates a ValidityPeriod that starts at t and lasts for d.
+// Timestamps are truncated to second precision.
+func MakeValidityPeriod(t time.Time, d time.Duration) ValidityPeriod {
+	return ValidityPeriod{
+		NotBefore: t.UTC().Truncate(time.Second),
+		NotAfter:  t.Add(d).UTC().Truncate(time.Second),
+	}
+}
+
+// Includes determines whether t is within this validity period.
+func (v ValidityPeriod) Includes(t time.Time) bool {
+	return !t.Before(v.NotBefore) && !t.After(v.NotAfter)
+}
+
+// Intersect returns the overlap of two validity periods.
+// If they do not overlap, the result is empty, i.e. NotBefore is after NotAfter, and Includes always returns false.
+func (v ValidityPeriod) Intersect(other ValidityPeriod) (r ValidityPeriod) {
+	r = v
+	if other.NotBefore.After(r.NotBefore) {
+		r.NotBefore = other.NotBefore
+	}
+	if other.NotAfter.Before(r.NotAfter) {
+		r.NotAfter = other.NotAfter
+	}
+	return r
+}
+
+func (v ValidityPeriod) String() string {
+	return v.NotBefore.UTC().Format(validityPeriodFormat) + "-" + v.NotAfter.UTC().Format(validityPeriodFormat)
 }
 
 // MarshalTlv encodes this ValidityPeriod.
 func (v ValidityPeriod) MarshalTlv() (typ uint32, value []byte, e error) {
-	return tlv.EncodeTlv(an.TtValidityPeriod, tlv.MakeElementNNI(an.TtNotBefore, v.NotBefore), tlv.MakeElement(an.TtNotAfter, v.NotAfter))
+	notBefore, e := encodeValidityTimestamp(v.NotBefore)
+	if e != nil {
+		return 0, nil, e
+	}
+	notAfter, e := encodeValidityTimestamp(v.NotAfter)
+	if e != nil {
+		return 0, nil, e
+	}
+	return tlv.EncodeTlv(an.TtValidityPeriod, tlv.MakeElement(an.TtNotBefore, notBefore), tlv.MakeElement(an.TtNotAfter, notAfter))
+}
+
+// UnmarshalBinary decodes from TLV-VALUE.
+// It requires exactly one NotBefore followed by one NotAfter, and NotBefore must not be after NotAfter.
+func (v *ValidityPeriod) UnmarshalBinary(wire []byte) (e error) {
+	*v = ValidityPeriod{}
+	d := tlv.Decoder(wire)
+	fields := d.Elements()
+	if e := d.ErrUnlessEOF(); e != nil {
+		return e
+	}
+	if len(fields) != 2 || fields[0].Type != an.TtNotBefore || fields[1].Type != an.TtNotAfter {
+		return ErrValidityPeriod
+	}
+	if v.NotBefore, e = decodeValidityTimestamp(fields[0].Value); e != nil {
+		return e
+	}
+	if v.NotAfter, e = decodeValidityTimestamp(fields[1].Value); e != nil {
+		return e
+	}
+	if v.NotBefore.After(v.NotAfter) {
+		return ErrValidityPeriod
+	}
+	return nil
+}
+
+func encodeValidityTimestamp(t time.Time) ([]byte, error) {
+	t = t.UTC()
+	if t.Year() < 0 || t.Year() > 9999 {
+		return nil, ErrValidityPeriod
+	}
+	return []byte(t.Format(validityPeriodFormat)), nil
+}
+
+func decodeValidityTimestamp(value []byte) (t time.Time, e error) {
+	if len(value) != len(validityPeriodFormat) {
+		return t, ErrValidityPeriod
+	}
+	for i, ch := range value {
+		if i == 8 {
+			if ch != 'T' {
+				return t, ErrValidityPeriod
+			}
+		} else if ch < '0' || ch > '9' {
+			return t, ErrValidityPeriod
+		}
+	}
+	if t, e = time.Parse(validityPeriodFormat, string(value)); e != nil {
+		return t, ErrValidityPeriod
+	}
+	return t, nil
+}
+
+// ValidityPeriod decodes the ValidityPeriod extension.
+// It returns ErrValidityPeriod if the extension is absent or malformed.
+func (si SigInfo) ValidityPeriod() (v ValidityPeriod, e error) {
+	for _, ext := range si.Extensions {
+		if ext.Type == an.TtValidityPeriod {
+			e = v.UnmarshalBinary(ext.Value)
+			return v, e
+		}
+	}
+	return v, ErrValidityPeriod
+}
+
+// SetValidityPeriod adds or replaces the ValidityPeriod extension.
+// To include a ValidityPeriod in a signed packet, set it on the packet's SigInfo before signing.
+func (si *SigInfo) SetValidityPeriod(v ValidityPeriod) error {
+	wire, e := tlv.Encode(v)
+	if e != nil {
+		return e
+	}
+	var ext tlv.Element
+	if _, e = ext.Decode(wire); e != nil {
+		return e
+	}
+
+	for i, old := range si.Extensions {
+		if old.Type == an.TtValidityPeriod {
+			si.Extensions[i] = ext
+			return nil
+		}
+	}
+	si.Extensions = append(si.Extensions, ext)
+	return nil
+}
+
+func init() {
+	RegisterSigInfoExtension(an.TtValidityPeriod)
 }
@@ -0,0 +1,103 @@
+package ndn_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/eric135/go-ndn"
+	"github.com/eric135/go-ndn/an"
+	"github.com/eric135/go-ndn/tlv"
+)
+
+func TestValidityPeriodEncode(t *testing.T) {
+	assert, require := makeAR(t)
+
+	vp := ndn.ValidityPeriod{
+		NotBefore: time.Date(2020, 11, 15, 1, 2, 3, 0, time.UTC),
+		NotAfter:  time.Date(2021, 12, 31, 23, 59, 59, 0, time.UTC),
+	}
+	wire, e := tlv.Encode(vp)
+	require.NoError(e)
+	assert.Equal(bytesFromHex("FD00FD26 FD00FE0F3230323031313135543031303230 33 FD00FF0F3230323131323331543233353935 39"), wire)
+	assert.Equal("20201115T010203-20211231T235959", vp.String())
+
+	var decoded ndn.ValidityPeriod
+	require.NoError(decoded.UnmarshalBinary(wire[4:]))
+	assert.Equal(vp, decoded)
+
+	// non-UTC timezone is converted to UTC
+	vp.NotBefore = time.Date(2020, 11, 15, 9, 2, 3, 0, time.FixedZone("", 8*3600))
+	wire2, e := tlv.Encode(vp)
+	require.NoError(e)
+	assert.Equal(wire, wire2)
+
+	vp.NotAfter = time.Date(10000, 1, 1, 0, 0, 0, 0, time.UTC)
+	_, e = tlv.Encode(vp)
+	assert.Error(e)
+}
+
+func TestValidityPeriodDecode(t *testing.T) {
+	assert, _ := makeAR(t)
+
+	for _, input := range []string{
+		"",
+		"FD00FE0F3230323031313135543031303230 33",                                              // missing NotAfter
+		"FD00FF0F3230323131323331543233353935 39 FE0F3230323031313135543031303230 33",          // wrong order
+		"FD00FE0E3230323031313135543031303230 FD00FF0F3230323131323331543233353935 39",         // NotBefore too short
+		"FD00FE0F3230323031313135583031303230 33 FD00FF0F3230323131323331543233353935 39",      // NotBefore missing T
+		"FD00FE0F2B30323031313135543031303230 33 FD00FF0F3230323131323331543233353935 39",      // NotBefore has sign
+		"FD00FE0F3230323031333135543031303230 33 FD00FF0F3230323131323331543233353935 39",      // NotBefore month 13
+		"FD00FE0F3230323231313135543031303230 33 FD00FF0F3230323131323331543233353935 39",      // NotBefore after NotAfter
+		"FD00FE0F3230323031313135543031303230 33 FD00FF0F3230323131323331543233353935 39 0100", // extra element
+	} {
+		var vp ndn.ValidityPeriod
+		assert.Error(vp.UnmarshalBinary(bytesFromHex(input)), input)
+	}
+}
+
+func TestValidityPeriodIntersect(t *testing.T) {
+	assert, _ := makeAR(t)
+
+	t0 := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	vp := ndn.MakeValidityPeriod(t0, 10*time.Hour)
+	assert.True(vp.Includes(t0))
+	assert.True(vp.Includes(t0.Add(10 * time.Hour)))
+	assert.False(vp.Includes(t0.Add(-time.Second)))
+	assert.False(vp.Includes(t0.Add(10*time.Hour + time.Second)))
+
+	r := vp.Intersect(ndn.MakeValidityPeriod(t0.Add(5*time.Hour), 10*time.Hour))
+	assert.Equal(t0.Add(5*time.Hour), r.NotBefore)
+	assert.Equal(t0.Add(10*time.Hour), r.NotAfter)
+
+	r = vp.Intersect(ndn.MakeValidityPeriod(t0.Add(11*time.Hour), time.Hour))
+	assert.False(r.Includes(t0.Add(10 * time.Hour)))
+	assert.False(r.Includes(t0.Add(11 * time.Hour)))
+}
+
+func TestSigInfoValidityPeriod(t *testing.T) {
+	assert, require := makeAR(t)
+
+	var si ndn.SigInfo
+	_, e := si.ValidityPeriod()
+	assert.Error(e)
+
+	vp := ndn.MakeValidityPeriod(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), time.Hour)
+	require.NoError(si.SetValidityPeriod(vp))
+	vp.NotAfter = vp.NotAfter.Add(time.Hour)
+	require.NoError(si.SetValidityPeriod(vp))
+	assert.Len(si.Extensions, 1)
+	assert.EqualValues(an.TtValidityPeriod, si.Extensions[0].Type)
+
+	data := ndn.MakeData("/A")
+	data.SigInfo = &si
+	require.NoError(ndn.DigestSigning.Sign(&data))
+	wire, e := tlv.Encode(data)
+	require.NoError(e)
+
+	var pkt ndn.Packet
+	require.NoError(tlv.Decode(wire, &pkt))
+	require.NotNil(pkt.Data.SigInfo)
+	decoded, e := pkt.Data.SigInfo.ValidityPeriod()
+	assert.NoError(e)
+	assert.Equal(vp, decoded)
+}